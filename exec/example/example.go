@@ -39,8 +39,13 @@ func main() {
 		println(err.Error())
 		return
 	}
-	locserver.StartTreeManager(10000, true)
+	if err := locserver.StartTreeManager(locserver.QuadTreeIndex, 10000, true); err != nil {
+		println(err.Error())
+		return
+	}
 	http.Handle("/loc", websocket.Handler(locserver.HandleLocationService))
+	http.HandleFunc("/heatmap", locserver.HandleHeatmap)
+	http.HandleFunc("/clusters", locserver.HandleClusters)
 	http.Handle("/msg", websocket.Handler(msgserver.HandleMessageService))
 	http.HandleFunc("/id", idProvider)
 	http.Handle("/", http.FileServer(http.Dir(pwd+"/html/")))
@@ -16,6 +16,7 @@ var iFile []byte
 var minTreeMax *int64 = flag.Int64("treeSize", 1000, "The initialisation size of the quadtree")
 var trackMovement *bool = flag.Bool("m", false, "Broadcast fine grained movement of users")
 var threads *int = flag.Int("t", 1, "The number of threads available to the runtime")
+var index *string = flag.String("index", locserver.QuadTreeIndex, "The spatial index users are stored in: quadtree, hashgrid or zorder")
 
 func init() {
 	flag.Parse()
@@ -25,6 +26,11 @@ func init() {
 func main() {
 	logutil.ServerStarted("Location")
 	http.Handle("/loc", websocket.Handler(locserver.HandleLocationService))
-	locserver.StartTreeManager(*minTreeMax, *trackMovement)
+	http.HandleFunc("/heatmap", locserver.HandleHeatmap)
+	http.HandleFunc("/clusters", locserver.HandleClusters)
+	if err := locserver.StartTreeManager(*index, *minTreeMax, *trackMovement); err != nil {
+		println(err.Error())
+		return
+	}
 	http.ListenAndServe(":8002", nil)
 }
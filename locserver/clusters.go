@@ -0,0 +1,67 @@
+package locserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fmstephe/location_server/logutil"
+	"github.com/fmstephe/location_server/quadtree"
+	"github.com/fmstephe/location_server/user"
+	"net/http"
+)
+
+// The number of clusters across the larger side of the area when the request doesn't give a resolution
+const defaultClusterDivisions = 64
+
+// The most user ids sent as representatives of each cluster
+const clusterIds = 5
+
+// A cluster of users in the json reply to a clusters request
+// (Lat,Lng) is the centroid of the users, Ids holds the ids of a few of them.
+type clusterMsg struct {
+	Lat, Lng float64
+	Count    int
+	Ids      []string
+}
+
+// Serves clusters of the users located within an area, for drawing a map at any zoom level
+// The area is bounded by the optional query parameters south, north, west and east,
+// in degrees, and is the whole world by default. The optional query parameter
+// resolution gives the largest extent, in degrees, of any cluster. By default the
+// larger side of the area is divided into 64.
+// The clusters are drawn from a snapshot of the tree so location updates carry on meanwhile.
+func HandleClusters(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	south, north, west, east, err := areaParams(q)
+	resolution := max(north-south, east-west) / defaultClusterDivisions
+	err = firstErr(err, floatParam(q.Get("resolution"), &resolution))
+	if err == nil && !(resolution > 0) {
+		err = fmt.Errorf("Cluster resolution must be positive. resolution: %f", resolution)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	v := quadtree.NewViewP(south, north, west, east)
+	clusters := TreeSnapshot().Clusters(v, resolution, clusterIds)
+	msgs := make([]clusterMsg, len(clusters))
+	for i, c := range clusters {
+		msgs[i] = clusterMsg{Lat: c.X, Lng: c.Y, Count: c.Count, Ids: clusterUserIds(c.Elems)}
+	}
+	buf, err := json.Marshal(msgs)
+	if err != nil {
+		logutil.LogFree(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf)
+}
+
+// Returns the id of each of usrs
+func clusterUserIds(usrs []*user.U) []string {
+	ids := make([]string, len(usrs))
+	for i, usr := range usrs {
+		ids[i] = usr.Id
+	}
+	return ids
+}
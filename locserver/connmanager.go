@@ -8,7 +8,6 @@ import (
 	"github.com/fmstephe/location_server/msgutil/msgdef"
 	"github.com/fmstephe/location_server/user"
 	"github.com/fmstephe/simpleid"
-	"math"
 )
 
 var iOpErr = errors.New("Illegal Message Op. Operation unrecognised or provided in illegal order.")
@@ -16,20 +15,14 @@ var idMap = simpleid.NewIdMap()
 
 // Represents a task for the tree manager.
 type task struct {
-	tId        uint            // The transaction id for this task
-	op         msgdef.ClientOp // The operation to perform for this task
-	usr        *user.U         // The state of the user for this task
-	olat, olng float64         // The position of the user, if it has changed
+	tId uint            // The transaction id for this task
+	op  msgdef.ClientOp // The operation to perform for this task
+	usr *user.U         // The state of the user for this task
 }
 
 // Safely creates a new task struct, in particular duplicating usr
 func newTask(tId uint, op msgdef.ClientOp, usr *user.U) *task {
-	return &task{tId: tId, op: op, usr: usr.Copy(), olat: math.NaN(), olng: math.NaN()}
-}
-
-// Safely creates a new task struct, in particular duplicating usr
-func newMoveTask(tId uint, op msgdef.ClientOp, usr *user.U, olat, olng float64) *task {
-	return &task{tId: tId, op: op, usr: usr.Copy(), olat: olat, olng: olng}
+	return &task{tId: tId, op: op, usr: usr.Copy()}
 }
 
 // This is the websocket connection handling function
@@ -135,10 +128,8 @@ func processMove(tId uint, locMsg *msgdef.CLocMsg, usr *user.U) func() error {
 		if locMsg.Op != msgdef.CMoveOp {
 			return iOpErr
 		}
-		olat := usr.Lat
-		olng := usr.Lng
 		usr.Move(locMsg.Lat, locMsg.Lng)
-		msg := newMoveTask(tId, msgdef.CMoveOp, usr, olat, olng)
+		msg := newTask(tId, msgdef.CMoveOp, usr)
 		forwardMsg(msg)
 		return nil
 	}
@@ -0,0 +1,110 @@
+package locserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fmstephe/location_server/logutil"
+	"github.com/fmstephe/location_server/quadtree"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// The number of cells along each axis of a heatmap when the request doesn't say
+const defaultHeatmapCells = 256
+
+// The most cells allowed along each axis of a heatmap
+const maxHeatmapCells = 1024
+
+// The json reply to a heatmap request
+// Counts[i][j] is the number of users in the i-th band of latitude, from south to north,
+// and the j-th band of longitude, from west to east.
+type heatmapMsg struct {
+	South, North, West, East float64
+	Counts                   [][]int
+}
+
+// Serves a heatmap of the number of users located in each cell of a grid laid over an area
+// The area is bounded by the optional query parameters south, north, west and east,
+// in degrees, and is the whole world by default. The optional query parameters
+// latCells and lngCells give the number of cells along each axis, 256 by default.
+// The heatmap is drawn from a snapshot of the tree so location updates carry on meanwhile.
+func HandleHeatmap(w http.ResponseWriter, r *http.Request) {
+	msg := &heatmapMsg{}
+	latCells, lngCells := defaultHeatmapCells, defaultHeatmapCells
+	q := r.URL.Query()
+	var err error
+	msg.South, msg.North, msg.West, msg.East, err = areaParams(q)
+	err = firstErr(err, cellsParam(q.Get("latCells"), &latCells))
+	err = firstErr(err, cellsParam(q.Get("lngCells"), &lngCells))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	v := quadtree.NewViewP(msg.South, msg.North, msg.West, msg.East)
+	msg.Counts = TreeSnapshot().Heatmap(v, latCells, lngCells)
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		logutil.LogFree(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf)
+}
+
+// Returns the area given by the optional query parameters south, north, west and east,
+// in degrees, the whole world by default
+// An area whose bounds aren't finite, or are inverted, is an error.
+func areaParams(q url.Values) (south, north, west, east float64, err error) {
+	south, north, west, east = maxSouthDeg, maxNorthDeg, maxWestDeg, maxEastDeg
+	err = floatParam(q.Get("south"), &south)
+	err = firstErr(err, floatParam(q.Get("north"), &north))
+	err = firstErr(err, floatParam(q.Get("west"), &west))
+	err = firstErr(err, floatParam(q.Get("east"), &east))
+	if err == nil && (math.IsInf(south, 0) || math.IsInf(north, 0) || math.IsInf(west, 0) || math.IsInf(east, 0)) {
+		err = fmt.Errorf("Area bounds must be finite. south: %f north: %f west: %f east: %f", south, north, west, east)
+	}
+	if err == nil && !(south <= north && west <= east) {
+		err = fmt.Errorf("Area bounds are inverted. south: %f north: %f west: %f east: %f", south, north, west, east)
+	}
+	return
+}
+
+// Parses str into f, leaving f unchanged if str is empty
+func floatParam(str string, f *float64) error {
+	if str == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// Parses str into cells, leaving cells unchanged if str is empty
+func cellsParam(str string, cells *int) error {
+	if str == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return err
+	}
+	if v < 1 || v > maxHeatmapCells {
+		return fmt.Errorf("Heatmap cells must be between 1 and %d. cells: %d", maxHeatmapCells, v)
+	}
+	*cells = v
+	return nil
+}
+
+// Returns err if it isn't nil, otherwise returns oErr
+func firstErr(err, oErr error) error {
+	if err != nil {
+		return err
+	}
+	return oErr
+}
@@ -4,176 +4,264 @@ import (
 	"fmt"
 	"github.com/fmstephe/location_server/logutil"
 	"github.com/fmstephe/location_server/msgutil/msgdef"
+	"github.com/fmstephe/location_server/msgutil/msgwriter"
 	"github.com/fmstephe/location_server/quadtree"
 	"github.com/fmstephe/location_server/user"
+	"time"
 )
 
-// Hardcoded values for the distance within which users are visible to each other
+// Hardcoded value for the distance within which users are visible to each other
 // Should be configurable
+const nearbyMetres = 1000.0
+
+// The most nearby users a newly located user is made visible to, so that
+// locating in a crowded area doesn't flood the new user with messages
+const maxInitVisible = 100
+
+// The smallest area, in degrees of latitude and longitude, the quadtree divides down to.
+// About a tenth of a metre, users packed closer than this, like a crowd at a stadium,
+// share an overflowing leaf rather than dividing the tree ever deeper
+const minCellDeg = 1e-6
+
+// The size, in degrees of latitude and longitude, of each cell of a hash grid index
+const gridCellDeg = 0.5
+
+// How often the tree manager logs the statistics of its index
+const statsInterval = 5 * time.Minute
+
+// The spatial indexes the tree manager can store users in
 const (
-	nearbyMetresNS = 1000.0
-	nearbyMetresEW = 1000.0
+	QuadTreeIndex = "quadtree"
+	HashGridIndex = "hashgrid"
+	ZOrderIndex   = "zorder"
 )
 
+// The users managed by the tree manager, each keyed by its MsgWriter
+// The MsgWriter is the only stable user field, see user.U.Equiv, so the tree manager
+// always knows where each user is stored without being told where it was.
+type userTree = quadtree.Keyed[*msgwriter.W, *user.U]
+
+// Returns the key of usr in a userTree
+func userKey(usr *user.U) *msgwriter.W {
+	return usr.MsgWriter
+}
+
+// Returns a new empty index, of the kind named, covering the whole globe
+// minTreeMax is the static allocation of a quadtree index, it is ignored by the others.
+// Returns an error if index names none of QuadTreeIndex, HashGridIndex or ZOrderIndex
+func newUserIndex(index string, minTreeMax int64) (quadtree.Index[*user.U], error) {
+	switch index {
+	case QuadTreeIndex:
+		tree := quadtree.New[*user.U](maxSouthDeg, maxNorthDeg, maxWestDeg, maxEastDeg, minTreeMax)
+		tree.SetDepthLimits(quadtree.DefaultMaxDepth, minCellDeg)
+		return tree, nil
+	case HashGridIndex:
+		cols := int((maxNorthDeg - maxSouthDeg) / gridCellDeg)
+		rows := int((maxEastDeg - maxWestDeg) / gridCellDeg)
+		return quadtree.NewHashGrid[*user.U](maxSouthDeg, maxNorthDeg, maxWestDeg, maxEastDeg, cols, rows), nil
+	case ZOrderIndex:
+		return quadtree.NewZOrderIndex[*user.U](maxSouthDeg, maxNorthDeg, maxWestDeg, maxEastDeg), nil
+	}
+	return nil, fmt.Errorf("Unknown index %q, expecting %q, %q or %q", index, QuadTreeIndex, HashGridIndex, ZOrderIndex)
+}
+
 // Single channel funnels all messages coming into the tree manager
 // As a simple global variable this is a bottleneck (top of the list for performance upgrade)
 var taskChan = make(chan *task, 255)
 
+// Requests for a snapshot of the tree, each is answered on the channel sent
+var snapshotChan = make(chan chan *quadtree.Snapshot[*user.U])
+
 // Starts a goroutine looping listening for messsages on taskChan to process
-func StartTreeManager(minTreeMax int64, trackMovement bool) {
+// Users are stored in the index named, one of QuadTreeIndex, HashGridIndex or ZOrderIndex,
+// with their latitude as x and their longitude as y
+// Returns an error, without starting, if index is unknown
+func StartTreeManager(index string, minTreeMax int64, trackMovement bool) error {
+	idx, err := newUserIndex(index, minTreeMax)
+	if err != nil {
+		return err
+	}
+	tree := quadtree.NewKeyed(idx, userKey)
 	go func() {
-		tree := quadtree.NewQuadTree(maxSouthMetres, maxNorthMetres, maxWestMetres, maxEastMetres, minTreeMax)
+		statsTicker := time.NewTicker(statsInterval)
 		for {
-			msg := <-taskChan
-			switch msg.op {
-			case msgdef.CInitLocOp:
-				handleInitLoc(msg, tree)
-			case msgdef.CRemoveOp:
-				handleRemove(msg, tree)
-			case msgdef.CMoveOp:
-				handleMove(msg, tree, trackMovement)
+			select {
+			case msg := <-taskChan:
+				switch msg.op {
+				case msgdef.CInitLocOp:
+					handleInitLoc(msg, tree)
+				case msgdef.CRemoveOp:
+					handleRemove(msg, tree)
+				case msgdef.CMoveOp:
+					handleMove(msg, tree, trackMovement)
+				}
+			case reply := <-snapshotChan:
+				reply <- tree.Snapshot()
+			case <-statsTicker.C:
+				logStats(idx)
 			}
 		}
 	}()
+	return nil
+}
+
+// Returns a point-in-time snapshot of the tree manager's index
+// The snapshot may be surveyed freely without holding up the tree manager.
+// Must not be called before StartTreeManager.
+func TreeSnapshot() *quadtree.Snapshot[*user.U] {
+	reply := make(chan *quadtree.Snapshot[*user.U])
+	snapshotChan <- reply
+	return <-reply
 }
 
 // Handles initial location tasks
 // An initial location message has the following effect
-// 1: The user is added to the quadtree at its initial location
-// 2: All nearby users to the new user and notified
-// 3: Symmetrically the new user is notified of all nearby users
-func handleInitLoc(initLoc *task, tree quadtree.T) {
+// 1: The user is added to the index at its initial location
+// 2: Nearby users, up to maxInitVisible of them, are notified of the new user
+// 3: Symmetrically the new user is notified of those same nearby users
+func handleInitLoc(initLoc *task, tree *userTree) {
 	usr := initLoc.usr
-	mNS, mEW := metresFromOrigin(usr.Lat, usr.Lng)
-	locLog(initLoc.tId, usr.Id, "InitLoc Request", mNS, mEW)
-	vs := []*quadtree.View{nearbyView(mNS, mEW)}
-	tree.Survey(vs, initLocFun(initLoc.tId, usr))
-	tree.Insert(mNS, mEW, usr)
+	locLog(initLoc.tId, usr.Id, "InitLoc Request", usr.Lat, usr.Lng)
+	tree.SurveyUntil(nearbyRegion(usr.Lat, usr.Lng), initLocFun(initLoc.tId, usr))
+	if err := tree.Insert(usr.Lat, usr.Lng, usr); err != nil {
+		logutil.Log(initLoc.tId, usr.Id, err.Error())
+	}
 }
 
 // Handles Remove tasks
 // A remove task has the following effect
-// 1: The user is removed from the quadtree
-// 2: All nearby users are notified
-func handleRemove(rmv *task, tree quadtree.T) {
+// 1: The user is removed from the index
+// 2: All users nearby where the user was stored are notified
+// A user who was never located is not in the index, and nobody is notified.
+func handleRemove(rmv *task, tree *userTree) {
 	usr := rmv.usr
-	mNS, mEW := metresFromOrigin(usr.Lat, usr.Lng)
-	locLog(rmv.tId, usr.Id, "Remove Request", mNS, mEW)
-	deleteUsr(mNS, mEW, usr, tree)
-	vs := []*quadtree.View{nearbyView(mNS, mEW)}
-	tree.Survey(vs, removeFun(rmv.tId, usr))
+	locLog(rmv.tId, usr.Id, "Remove Request", usr.Lat, usr.Lng)
+	loc, _, ok := tree.Get(userKey(usr))
+	if !ok {
+		return
+	}
+	tree.Remove(userKey(usr))
+	tree.SurveyRegion(nearbyRegion(loc.X, loc.Y), removeFun(rmv.tId, usr))
 }
 
 // Handles move tasks
 // A move task has the following effect
-// 1: The user is removed from the quadtree at its old location
-// 2: The user is inserted into the quadtree at its new location
-// 3: All users who could see the user but can't now are notified
-// 4: All users who could not see the user but can now are notified
-// 5: if (trackMovement) All users who can see the user in both the old and new position are notified
-func handleMove(mv *task, tree quadtree.T, trackMovement bool) {
+// 1: The user is moved from its old location to its new location in the index
+// 2: All users who could see the user but can't now are notified
+// 3: All users who could not see the user but can now are notified
+// 4: if (trackMovement) All users who can see the user in both the old and new position are notified
+// The old position is wherever the user is stored in the index.
+// The copy of usr stored in the index is moved as it is, its lat/lng are left stale.
+// A stored user's location is always taken from the index, never from the stored copy,
+// so the copy is never changed and snapshots of the index never see a user change.
+// If the user isn't stored, or the new position lies outside the index, the user stays put
+// and nobody is notified.
+func handleMove(mv *task, tree *userTree, trackMovement bool) {
 	usr := mv.usr
-	oMNS, oMEW := metresFromOrigin(mv.olat, mv.olng)
-	nMNS, nMEW := metresFromOrigin(usr.Lat, usr.Lng)
-	locLogL(mv.tId, usr.Id, "Relocate Request", oMNS, oMEW, nMNS, oMEW)
-	deleteUsr(oMNS, oMEW, usr, tree)
-	tree.Insert(nMNS, nMEW, usr)
-	nView := nearbyView(nMNS, nMEW)
-	oView := nearbyView(oMNS, oMEW)
+	old, _, ok := tree.Get(userKey(usr))
+	if !ok {
+		logutil.Log(mv.tId, usr.Id, fmt.Sprintf("Relocate Request - %s", quadtree.UnknownKeyErr.Error()))
+		return
+	}
+	locLogL(mv.tId, usr.Id, "Relocate Request", old.X, old.Y, usr.Lat, usr.Lng)
+	if err := tree.MoveTo(userKey(usr), usr.Lat, usr.Lng); err != nil {
+		logutil.Log(mv.tId, usr.Id, err.Error())
+		return
+	}
+	nRegion := nearbyRegion(usr.Lat, usr.Lng)
+	oRegion := nearbyRegion(old.X, old.Y)
 	// Alert out of bounds users
-	nvViews := oView.Subtract(nView)
-	tree.Survey(nvViews, notVisibleFun(mv.tId, usr))
+	tree.SurveyRegion(quadtree.Difference(oRegion, nRegion), notVisibleFun(mv.tId, usr))
 	// Alert newly visible users
-	vViews := nView.Subtract(oView)
-	tree.Survey(vViews, visibleFun(mv.tId, usr))
+	tree.SurveyRegion(quadtree.Difference(nRegion, oRegion), visibleFun(mv.tId, usr))
 	// Alert watching users of the relocation
 	if trackMovement {
-		movedView := []*quadtree.View{nView.Intersect(oView)}
-		tree.Survey(movedView, movedFun(mv.tId, usr))
+		tree.SurveyRegion(quadtree.Intersection(nRegion, oRegion), movedFun(mv.tId, usr))
 	}
 }
 
-// Deletes usr from tree at the given coords
-func deleteUsr(mNS, mEW float64, usr *user.U, tree quadtree.T) {
-	v := quadtree.PointViewP(mNS, mEW)
-	pred := func(_, _ float64, e interface{}) bool {
-		oUsr := e.(*user.U)
-		return usr.Equiv(oUsr)
-	}
-	tree.Del(v, pred)
-}
-
 // Returns a function used for alerting users that another user has been added to the system
-func initLocFun(tId uint, usr *user.U) func(mNS, mEW float64, e interface{}) {
-	return func(mNS, mEW float64, e interface{}) {
-		oUsr := e.(*user.U)
+// The function stops the survey once maxInitVisible users have been alerted
+func initLocFun(tId uint, usr *user.U) func(lat, lng float64, oUsr *user.U) bool {
+	visible := 0
+	return func(lat, lng float64, oUsr *user.U) bool {
 		if !usr.Equiv(oUsr) {
-			broadcastSend(tId, msgdef.SVisibleOp, usr, oUsr)
-			broadcastSend(tId, msgdef.SVisibleOp, oUsr, usr)
+			broadcastSend(tId, msgdef.SVisibleOp, usr, usr.Lat, usr.Lng, oUsr)
+			broadcastSend(tId, msgdef.SVisibleOp, oUsr, lat, lng, usr)
+			visible++
 		}
+		return visible < maxInitVisible
 	}
 }
 
 // Returns a function used for alerting users that another user has been removed from the system
-func removeFun(tId uint, usr *user.U) func(mNS, mEW float64, e interface{}) {
-	return func(mNS, mEW float64, e interface{}) {
-		oUsr := e.(*user.U)
-		broadcastSend(tId, msgdef.SNotVisibleOp, usr, oUsr)
+func removeFun(tId uint, usr *user.U) func(lat, lng float64, oUsr *user.U) {
+	return func(lat, lng float64, oUsr *user.U) {
+		broadcastSend(tId, msgdef.SNotVisibleOp, usr, usr.Lat, usr.Lng, oUsr)
 	}
 }
 
 // Returns a function used for alerting users that another user has just left the visible range
-func notVisibleFun(tId uint, usr *user.U) func(mNS, mEW float64, e interface{}) {
-	return func(mNS, mEW float64, e interface{}) {
-		oUsr := e.(*user.U)
-		broadcastSend(tId, msgdef.SNotVisibleOp, usr, oUsr)
-		broadcastSend(tId, msgdef.SNotVisibleOp, oUsr, usr)
+func notVisibleFun(tId uint, usr *user.U) func(lat, lng float64, oUsr *user.U) {
+	return func(lat, lng float64, oUsr *user.U) {
+		broadcastSend(tId, msgdef.SNotVisibleOp, usr, usr.Lat, usr.Lng, oUsr)
+		broadcastSend(tId, msgdef.SNotVisibleOp, oUsr, lat, lng, usr)
 	}
 }
 
 // Returns a function used for alerting users that another user has entered the visible range
-func visibleFun(tId uint, usr *user.U) func(mNS, mEW float64, e interface{}) {
-	return func(mNS, mEW float64, e interface{}) {
-		oUsr := e.(*user.U)
+func visibleFun(tId uint, usr *user.U) func(lat, lng float64, oUsr *user.U) {
+	return func(lat, lng float64, oUsr *user.U) {
 		if !usr.Equiv(oUsr) {
-			broadcastSend(tId, msgdef.SVisibleOp, usr, oUsr)
-			broadcastSend(tId, msgdef.SVisibleOp, oUsr, usr)
+			broadcastSend(tId, msgdef.SVisibleOp, usr, usr.Lat, usr.Lng, oUsr)
+			broadcastSend(tId, msgdef.SVisibleOp, oUsr, lat, lng, usr)
 		}
 	}
 }
 
 // Returns a function used for alerting users that another user, within visible range, has changed position
-func movedFun(tId uint, usr *user.U) func(mNS, mEW float64, e interface{}) {
-	return func(mNS, mEW float64, e interface{}) {
-		oUsr := e.(*user.U)
+func movedFun(tId uint, usr *user.U) func(lat, lng float64, oUsr *user.U) {
+	return func(lat, lng float64, oUsr *user.U) {
 		if !usr.Equiv(oUsr) {
-			broadcastSend(tId, msgdef.SMovedOp, usr, oUsr)
+			broadcastSend(tId, msgdef.SMovedOp, usr, usr.Lat, usr.Lng, oUsr)
 		}
 	}
 }
 
-// Returns a View representing the area considered 'nearby' to the point (mNS,mEW)
-func nearbyView(mNS, mEW float64) *quadtree.View {
-	sth := mNS - nearbyMetresNS
-	nth := mNS + nearbyMetresNS
-	wst := mEW - nearbyMetresEW
-	est := mEW + nearbyMetresEW
-	return quadtree.NewViewP(sth, nth, wst, est)
+// Returns a Region representing the area considered 'nearby' to the point (lat,lng)
+// The region's bounding GeoView wraps across the antimeridian and over the poles.
+func nearbyRegion(lat, lng float64) quadtree.Region {
+	return quadtree.NewGeoCircle(lat, lng, nearbyMetres)
 }
 
-// Sends a message to oUsr informing him/her of a notification involving usr
-func broadcastSend(tId uint, op msgdef.ServerOp, usr *user.U, oUsr *user.U) {
-	locMsg := msgdef.SLocMsg{Op: op, Id: usr.Id, Lat: usr.Lat, Lng: usr.Lng}
+// Sends a message to oUsr informing them of a notification involving usr, located at (lat,lng)
+func broadcastSend(tId uint, op msgdef.ServerOp, usr *user.U, lat, lng float64, oUsr *user.U) {
+	locMsg := msgdef.SLocMsg{Op: op, Id: usr.Id, Lat: lat, Lng: lng}
 	sMsg := &msgdef.ServerMsg{Msg: locMsg, TId: tId, UId: usr.Id}
 	oUsr.MsgWriter.WriteMsg(sMsg)
 }
 
+// Logs the statistics of tree, warning if a quadtree has outgrown its static allocation
+// Indexes other than a quadtree only log the number of users they hold.
+func logStats(tree quadtree.Index[*user.U]) {
+	qt, ok := tree.(*quadtree.QuadTree[*user.U])
+	if !ok {
+		logutil.LogFree(fmt.Sprintf("Index Stats - %d users", tree.CountRegion(tree.View())))
+		return
+	}
+	stats := qt.Stats()
+	logutil.LogFree(fmt.Sprintf("Quadtree Stats - %s", stats.String()))
+	if stats.PoolExhausted() {
+		logutil.LogFree("Quadtree static allocation exhausted - consider increasing treeSize")
+	}
+}
+
 // Logs a task involving only a single location point
-func locLog(tId uint, uId, taskDesc string, mNS, mEW float64) {
-	logutil.Log(tId, uId, fmt.Sprintf("%s - mNS: %f mEW: %f", taskDesc, mNS, mEW))
+func locLog(tId uint, uId, taskDesc string, lat, lng float64) {
+	logutil.Log(tId, uId, fmt.Sprintf("%s - lat: %f lng: %f", taskDesc, lat, lng))
 }
 
 // Logs a task involving an old and new location point
-func locLogL(tId uint, uId, taskDesc string, oMNS, oMEW, nMNS, nMEW float64) {
-	logutil.Log(tId, uId, fmt.Sprintf("%s - oMNS: %f oMEW %f nMNS: %f nMEW %f", taskDesc, oMNS, oMEW, nMNS, nMEW))
+func locLogL(tId uint, uId, taskDesc string, oLat, oLng, nLat, nLng float64) {
+	logutil.Log(tId, uId, fmt.Sprintf("%s - oLat: %f oLng %f nLat: %f nLng %f", taskDesc, oLat, oLng, nLat, nLng))
 }
@@ -0,0 +1,128 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// An Aggregate summarises the elements lying within some part of a quadtree.
+// Count is the number of elements. Sum, Min and Max are taken over the key of
+// each element, see SetAggregateKey, and are all zero if the tree has no key.
+// Min and Max are also zero when Count is zero.
+type Aggregate struct {
+	Count    int
+	Sum      float64
+	Min, Max float64
+}
+
+// Adds o into a, as if every element summarised by o had been added to a
+func (a *Aggregate) merge(o Aggregate) {
+	if o.Count == 0 {
+		return
+	}
+	if a.Count == 0 {
+		*a = o
+		return
+	}
+	a.Count += o.Count
+	a.Sum += o.Sum
+	a.Min = math.Min(a.Min, o.Min)
+	a.Max = math.Max(a.Max, o.Max)
+}
+
+// Adds each of elems into a, key may be nil
+func aggregateElems[E any](a *Aggregate, key func(e E) float64, elems []E) {
+	if key == nil {
+		a.Count += len(elems)
+		return
+	}
+	for _, e := range elems {
+		k := key(e)
+		a.merge(Aggregate{1, k, k, k})
+	}
+}
+
+func (a Aggregate) String() string {
+	return fmt.Sprintf("Count %d Sum %f Min %f Max %f", a.Count, a.Sum, a.Min, a.Max)
+}
+
+// Sets the function used to give each element the key summed, and compared, by AggregateRegion
+// Every node in this tree holds the aggregate of the elements beneath it, so these
+// are all recalculated. It is cheapest to set the key before the tree is filled.
+// A nil key aggregates only the number of elements.
+func (r *QuadTree[E]) SetAggregateKey(key func(e E) float64) {
+	r.key = key
+	r.reaggregate(&r.rootNode)
+}
+
+// Recalculates the aggregate held by every node in the subtree pointed to by st
+func (r *QuadTree[E]) reaggregate(st *subtree[E]) {
+	if n, ok := r.own(st).(*node[E]); ok {
+		for i := range n.children {
+			r.reaggregate(&n.children[i])
+		}
+		n.resummarise(r.key)
+	}
+}
+
+// Returns the number of elements occurring within any view in vs in this tree
+func (r *QuadTree[E]) Count(vs []*View) int {
+	return r.rootNode.aggregate(views(vs), nil).Count
+}
+
+// Returns the number of elements occurring within reg in this tree
+// Whole subtrees lying inside reg are counted without visiting their elements.
+func (r *QuadTree[E]) CountRegion(reg Region) int {
+	return r.rootNode.aggregate(reg, nil).Count
+}
+
+// Returns the aggregate of every element occurring within reg in this tree
+// Whole subtrees lying inside reg are aggregated without visiting their elements.
+func (r *QuadTree[E]) AggregateRegion(reg Region) Aggregate {
+	return r.rootNode.aggregate(reg, r.key)
+}
+
+// Returns the aggregate of the elements in this leaf lying within reg, a nil reg includes every element
+func (l *leaf[E]) aggregate(reg Region, key func(e E) float64) Aggregate {
+	var a Aggregate
+	for i := range l.ps {
+		p := &l.ps[i]
+		if p.zeroed() {
+			break
+		}
+		if reg == nil || reg.contains(p.x, p.y) {
+			aggregateElems(&a, key, p.elems)
+		}
+	}
+	return a
+}
+
+// Returns the aggregate of the elements under this node lying within reg, a nil reg includes every element
+// If reg covers this node the aggregate it holds is returned directly.
+func (n *node[E]) aggregate(reg Region, key func(e E) float64) Aggregate {
+	if reg == nil || reg.covers(&n.view) {
+		if key == nil {
+			return Aggregate{Count: n.agg.Count}
+		}
+		return n.agg
+	}
+	var a Aggregate
+	for i := range n.children {
+		if reg.overlaps(n.children[i].View()) {
+			a.merge(n.children[i].aggregate(reg, key))
+		}
+	}
+	return a
+}
+
+// Recalculates the aggregate and coordinate sums held by this node from its children
+func (n *node[E]) resummarise(key func(e E) float64) {
+	n.agg = Aggregate{}
+	n.sumX, n.sumY = 0, 0
+	for i := range n.children {
+		n.agg.merge(n.children[i].aggregate(nil, key))
+		sx, sy := coordSums(n.children[i])
+		n.sumX += sx
+		n.sumY += sy
+	}
+}
@@ -0,0 +1,78 @@
+package quadtree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// A BoundsPolicy decides what a QuadTree does with a point lying outside its View
+type BoundsPolicy int
+
+const (
+	// Points outside the tree's View are rejected, Insert and Move return an error
+	Bounded BoundsPolicy = iota
+	// The tree's View is doubled, as many times as needed, until it contains the point
+	Growing
+)
+
+var OutOfBoundsErr = errors.New("Point lies outside the view of the quadtree")
+
+// Sets the policy used when a point outside this tree's View is inserted
+// A new tree is Bounded.
+func (r *QuadTree[E]) SetBoundsPolicy(policy BoundsPolicy) {
+	r.policy = policy
+}
+
+// Ensures that this tree's View contains (x,y), growing the tree if its policy allows.
+// Returns an error if (x,y) lies outside the tree and cannot be accommodated.
+// Points with an infinite or NaN coordinate are always rejected.
+func (r *QuadTree[E]) accommodate(x, y float64) error {
+	if r.View().contains(x, y) {
+		return nil
+	}
+	if r.policy != Growing || math.IsInf(x, 0) || math.IsInf(y, 0) || math.IsNaN(x) || math.IsNaN(y) {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, x, y, r.View())
+	}
+	for !r.View().contains(x, y) {
+		r.grow(x, y)
+	}
+	return nil
+}
+
+// Replaces the root of this tree with a new node whose view is twice the width and
+// height of the current root's, extended towards (x,y).
+// The old root becomes one of the children of the new root. The new root's quarters
+// are built directly, rather than by halving its view, so that the old root's quarter
+// is exactly its old view.
+func (r *QuadTree[E]) grow(x, y float64) {
+	old := r.rootNode
+	v := old.View()
+	w := math.Max(v.width(), 1)
+	h := math.Max(v.height(), 1)
+	lx, midx, rx := v.lx, v.rx, v.rx+w
+	if x < v.lx {
+		lx, midx, rx = v.lx-w, v.lx, v.rx
+	}
+	ty, midy, by := v.ty, v.by, v.by+h
+	if y < v.ty {
+		ty, midy, by = v.ty-h, v.ty, v.by
+	}
+	n := r.newNode(NewViewP(lx, rx, ty, by))
+	qs := []*View{
+		NewViewP(lx, midx, ty, midy),
+		NewViewP(midx, rx, ty, midy),
+		NewViewP(lx, midx, midy, by),
+		NewViewP(midx, rx, midy, by),
+	}
+	for i := range n.children {
+		if qs[i].eq(v) {
+			r.recycle(n.children[i])
+			n.children[i] = old
+		} else {
+			n.children[i].setView(qs[i])
+		}
+	}
+	n.resummarise(r.key)
+	r.rootNode = n
+}
@@ -12,14 +12,45 @@ var pointsLarge = 10000
 var repsSingle = 1
 var repsLarge = 10
 
-func makeTrees(tNum int, w, h float64) []T {
+// The index implementations which each workload is benchmarked against
+var benchIndexes = []struct {
+	name     string
+	newIndex func(w, h float64) T
+}{
+	{"QuadTree", newBenchQuadTree},
+	{"QuadTreeMedian", newBenchMedianTree},
+	{"HashGrid", func(w, h float64) T { return NewHashGrid[interface{}](0, w, 0, h, 32, 32) }},
+	{"ZOrder", func(w, h float64) T { return NewZOrderIndex[interface{}](0, w, 0, h) }},
+}
+
+func newBenchQuadTree(w, h float64) T {
+	return NewQuadTree(0, w, 0, h, 10000)
+}
+
+func newBenchMedianTree(w, h float64) T {
+	tree := New[interface{}](0, w, 0, h, 10000)
+	tree.SetSplitStrategy(MedianSplit)
+	return tree
+}
+
+// Runs bench as a sub-benchmark against each of benchIndexes
+func runIndexes(b *testing.B, bench func(b *testing.B, newIndex func(w, h float64) T)) {
+	for _, idx := range benchIndexes {
+		b.Run(idx.name, func(b *testing.B) {
+			bench(b, idx.newIndex)
+		})
+	}
+}
+
+func makeTrees(newIndex func(w, h float64) T, tNum int, w, h float64) []T {
 	trees := make([]T, tNum)
 	for i := range trees {
-		trees[i] = NewQuadTree(0, w, 0, h, 10000)
+		trees[i] = newIndex(w, h)
 	}
 	return trees
 }
 
+// Returns, for each of tNum trees, pNum points spread uniformly at random
 func makePoints(tNum, pNum int, w, h float64) [][]point {
 	points := make([][]point, tNum)
 	for i := range points {
@@ -28,9 +59,18 @@ func makePoints(tNum, pNum int, w, h float64) [][]point {
 	return points
 }
 
-func makeFilledTrees(tNum, pNum, reps int, w, h float64) []T {
-	trees := makeTrees(tNum, w, h)
-	points := makePoints(tNum, pNum, w, h)
+// Returns, for each of tNum trees, pNum points gathered into a few small cities
+func makeClusteredPoints(tNum, pNum int, w, h float64) [][]point {
+	points := make([][]point, tNum)
+	for i := range points {
+		points[i] = cities(OrigViewP(w, h), pNum)
+	}
+	return points
+}
+
+func makeFilledTrees(newIndex func(w, h float64) T, makePs func(tNum, pNum int, w, h float64) [][]point, tNum, pNum, reps int, w, h float64) []T {
+	trees := makeTrees(newIndex, tNum, w, h)
+	points := makePs(tNum, pNum, w, h)
 	for ti := range trees {
 		tree := trees[ti]
 		ps := points[ti]
@@ -45,10 +85,22 @@ func makeFilledTrees(tNum, pNum, reps int, w, h float64) []T {
 }
 
 func BenchmarkInsert(b *testing.B) {
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkInsert(b, newIndex, makePoints)
+	})
+}
+
+func BenchmarkInsertClustered(b *testing.B) {
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkInsert(b, newIndex, makeClusteredPoints)
+	})
+}
+
+func benchmarkInsert(b *testing.B, newIndex func(w, h float64) T, makePs func(tNum, pNum int, w, h float64) [][]point) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		trees := makeTrees(treeNum, width, height)
-		treePoints := makePoints(treeNum, pointsLarge, width, height)
+		trees := makeTrees(newIndex, treeNum, width, height)
+		treePoints := makePs(treeNum, pointsLarge, width, height)
 		b.StartTimer()
 		for j := range trees {
 			tree := trees[j]
@@ -61,17 +113,27 @@ func BenchmarkInsert(b *testing.B) {
 }
 
 func BenchmarkSurveyR(b *testing.B) {
-	benchmarkSurvey(b, pointsSmall, repsLarge)
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkSurvey(b, newIndex, makePoints, pointsSmall, repsLarge)
+	})
 }
 
 func BenchmarkSurveyS(b *testing.B) {
-	benchmarkSurvey(b, pointsLarge, repsSingle)
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkSurvey(b, newIndex, makePoints, pointsLarge, repsSingle)
+	})
+}
+
+func BenchmarkSurveyClustered(b *testing.B) {
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkSurvey(b, newIndex, makeClusteredPoints, pointsLarge, repsSingle)
+	})
 }
 
-func benchmarkSurvey(b *testing.B, pointNum, repNum int) {
+func benchmarkSurvey(b *testing.B, newIndex func(w, h float64) T, makePs func(tNum, pNum int, w, h float64) [][]point, pointNum, repNum int) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		trees := makeFilledTrees(treeNum, pointNum, repNum, width, height)
+		trees := makeFilledTrees(newIndex, makePs, treeNum, pointNum, repNum, width, height)
 		b.StartTimer()
 		for j := range trees {
 			tree := trees[j]
@@ -84,18 +146,65 @@ func benchmarkSurvey(b *testing.B, pointNum, repNum int) {
 	}
 }
 
+// Surveys a small view around each point, like the nearby surveys made by locserver
+func BenchmarkSurveyNearby(b *testing.B) {
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkSurveyNearby(b, newIndex, makePoints)
+	})
+}
+
+func BenchmarkSurveyNearbyClustered(b *testing.B) {
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkSurveyNearby(b, newIndex, makeClusteredPoints)
+	})
+}
+
+func benchmarkSurveyNearby(b *testing.B, newIndex func(w, h float64) T, makePs func(tNum, pNum int, w, h float64) [][]point) {
+	side := width / 1e4
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		trees := makeTrees(newIndex, treeNum, width, height)
+		treePoints := makePs(treeNum, pointsLarge, width, height)
+		for j := range trees {
+			for _, p := range treePoints[j] {
+				trees[j].Insert(p.x, p.y, "test")
+			}
+		}
+		b.StartTimer()
+		for j := range trees {
+			count := 0
+			fun := func(x, y float64, e interface{}) {
+				count++
+			}
+			for _, p := range treePoints[j] {
+				trees[j].Survey([]*View{NewViewP(p.x-side, p.x+side, p.y-side, p.y+side)}, fun)
+			}
+		}
+	}
+}
+
 func BenchmarkDeleteR(b *testing.B) {
-	benchmarkDelete(b, pointsSmall, repsLarge)
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkDelete(b, newIndex, makePoints, pointsSmall, repsLarge)
+	})
 }
 
 func BenchmarkDeleteS(b *testing.B) {
-	benchmarkDelete(b, pointsLarge, repsSingle)
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkDelete(b, newIndex, makePoints, pointsLarge, repsSingle)
+	})
+}
+
+func BenchmarkDeleteClustered(b *testing.B) {
+	runIndexes(b, func(b *testing.B, newIndex func(w, h float64) T) {
+		benchmarkDelete(b, newIndex, makeClusteredPoints, pointsLarge, repsSingle)
+	})
 }
 
-func benchmarkDelete(b *testing.B, pointNum, repNum int) {
+func benchmarkDelete(b *testing.B, newIndex func(w, h float64) T, makePs func(tNum, pNum int, w, h float64) [][]point, pointNum, repNum int) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		trees := makeFilledTrees(treeNum, pointNum, repNum, width, height)
+		trees := makeFilledTrees(newIndex, makePs, treeNum, pointNum, repNum, width, height)
 		b.StartTimer()
 		for j := range trees {
 			tree := trees[j]
@@ -105,10 +214,39 @@ func benchmarkDelete(b *testing.B, pointNum, repNum int) {
 				deleted = append(deleted, e)
 				return true
 			}
-			tree.Delete(q1, pred)
-			tree.Delete(q2, pred)
-			tree.Delete(q3, pred)
-			tree.Delete(q4, pred)
+			tree.Del(q1, pred)
+			tree.Del(q2, pred)
+			tree.Del(q3, pred)
+			tree.Del(q4, pred)
+		}
+	}
+}
+
+func BenchmarkBulkLoad(b *testing.B) {
+	benchmarkBulkLoad(b, newBenchQuadTree, makePoints)
+}
+
+func BenchmarkBulkLoadClustered(b *testing.B) {
+	b.Run("QuadTree", func(b *testing.B) {
+		benchmarkBulkLoad(b, newBenchQuadTree, makeClusteredPoints)
+	})
+	b.Run("QuadTreeMedian", func(b *testing.B) {
+		benchmarkBulkLoad(b, newBenchMedianTree, makeClusteredPoints)
+	})
+}
+
+func benchmarkBulkLoad(b *testing.B, newTree func(w, h float64) T, makePs func(tNum, pNum int, w, h float64) [][]point) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		trees := makeTrees(newTree, treeNum, width, height)
+		treePoints := makePs(treeNum, pointsLarge, width, height)
+		treeEntries := make([][]Entry[interface{}], treeNum)
+		for j := range treeEntries {
+			treeEntries[j] = makeEntries(treePoints[j], 1)
+		}
+		b.StartTimer()
+		for j := range trees {
+			trees[j].(*QuadTree[interface{}]).InsertMany(treeEntries[j])
 		}
 	}
 }
@@ -0,0 +1,192 @@
+package quadtree
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Returns a new QuadTree, storing elements of type E, whose View extends from
+// leftX to rightX across the x axis and
+// topY down to bottomY along the y axis
+// leftX < rightX
+// topY < bottomY
+// The tree is filled with entries, see InsertMany, building each node and leaf
+// directly rather than splitting leaves as they overflow.
+// Entries lying outside the tree's View are discarded.
+func NewBulk[E any](leftX, rightX, topY, bottomY float64, leafAllocation int64, entries []Entry[E]) *QuadTree[E] {
+	r := New[E](leftX, rightX, topY, bottomY, leafAllocation)
+	r.InsertMany(entries)
+	return r
+}
+
+// Inserts every entry into this tree
+// The entries are partitioned among the tree's subtrees in a single descent.
+// Wherever a leaf receives more points than it can hold it is replaced by a node
+// and the points are partitioned again among the node's new leaves, unless the leaf
+// can't be divided any further and overflows instead, see SetDepthLimits.
+// Entries lying outside this tree's View are accommodated according to the tree's
+// BoundsPolicy. Any entries which can't be are skipped, the rest are inserted and
+// an error is returned.
+func (r *QuadTree[E]) InsertMany(entries []Entry[E]) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var err error
+	rejected := 0
+	elems := make([]E, len(entries))
+	ps := make([]vpoint[E], 0, len(entries))
+	for i := range entries {
+		x, y := entries[i].X, entries[i].Y
+		if aErr := r.accommodate(x, y); aErr != nil {
+			err = aErr
+			rejected++
+			continue
+		}
+		elems[i] = entries[i].Elem
+		// Limit the capacity of each elems slice so that appending to it can't overwrite its neighbour
+		ps = append(ps, vpoint[E]{x: x, y: y, elems: elems[i : i+1 : i+1]})
+	}
+	insertMany(&r.rootNode, ps, r)
+	if rejected > 1 {
+		return fmt.Errorf("%d entries rejected, last %w", rejected, err)
+	}
+	return err
+}
+
+// Dels each element, e, in this tree which lies at one of the points in ps and for which pred(e) returns true
+// Every point is deleted from in a single descent of the tree.
+func (r *QuadTree[E]) DelMany(ps []Point, pred func(x, y float64, e E) bool) {
+	if len(ps) == 0 {
+		return
+	}
+	r.Del(newPointSet(ps), pred)
+}
+
+// Inserts each of ps into the subtree pointed to by st
+func insertMany[E any](st *subtree[E], ps []vpoint[E], r *QuadTree[E]) {
+	if len(ps) == 0 {
+		return
+	}
+	switch r.own(st).(type) {
+	case *node[E]:
+		n := (*st).(*node[E])
+		parts := partition(ps, n)
+		for i := range n.children {
+			insertMany(&n.children[i], parts[i], r)
+		}
+		n.resummarise(r.key)
+	case *leaf[E]:
+		l := (*st).(*leaf[E])
+		if !r.canDivide(&l.view) || l.fits(ps) {
+			for i := range ps {
+				l.insert(ps[i].x, ps[i].y, ps[i].elems, st, r)
+			}
+			return
+		}
+		for i := range l.ps {
+			if !l.ps[i].zeroed() {
+				ps = append(ps, l.ps[i])
+			}
+		}
+		*st = r.newSplitNode(l.View(), ps)
+		r.recycleLeaf(l)
+		insertMany(st, ps, r)
+	}
+}
+
+// Indicates whether the points in ps could be inserted into l without overflowing it
+// i.e. whether there are no more than LEAF_SIZE distinct locations among ps and l's points.
+// We stop looking as soon as one location too many is found.
+// An overflowing leaf never fits any more points.
+func (l *leaf[E]) fits(ps []vpoint[E]) bool {
+	if len(l.ps) > LEAF_SIZE {
+		return false
+	}
+	var locs [LEAF_SIZE]Point
+	n := 0
+	for ; n < len(l.ps) && !l.ps[n].zeroed(); n++ {
+		locs[n] = Point{l.ps[n].x, l.ps[n].y}
+	}
+NEXT_POINT:
+	for i := range ps {
+		for j := 0; j < n; j++ {
+			if ps[i].sameLoc(locs[j].X, locs[j].Y) {
+				continue NEXT_POINT
+			}
+		}
+		if n == LEAF_SIZE {
+			return false
+		}
+		locs[n] = Point{ps[i].x, ps[i].y}
+		n++
+	}
+	return true
+}
+
+// Rearranges ps, in place, so that the points lying within each of n's children
+// are grouped together, and returns the group for each child.
+// A point lying on the border of two children is placed with the first of them.
+// Points lying within none of n's children are discarded.
+func partition[E any](ps []vpoint[E], n *node[E]) (parts [4][]vpoint[E]) {
+	for i := range n.children {
+		view := n.children[i].View()
+		in := 0
+		for j := range ps {
+			if view.contains(ps[j].x, ps[j].y) {
+				ps[in], ps[j] = ps[j], ps[in]
+				in++
+			}
+		}
+		// Limit the capacity of each part so that appending to it can't overwrite the next part
+		parts[i] = ps[:in:in]
+		ps = ps[in:]
+	}
+	return
+}
+
+// A pointSet is a Region made up of a set of points, and nothing else.
+// The points are sorted by x so the points lying within a view can be
+// found by binary search.
+type pointSet []Point
+
+// Returns a new pointSet containing each of ps
+func newPointSet(ps []Point) pointSet {
+	set := make(pointSet, len(ps))
+	copy(set, ps)
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].X < set[j].X
+	})
+	return set
+}
+
+// Indicates whether (x,y) is one of the points in this set
+func (set pointSet) contains(x, y float64) bool {
+	for i := set.search(x); i < len(set) && set[i].X == x; i++ {
+		if set[i].Y == y {
+			return true
+		}
+	}
+	return false
+}
+
+// Indicates whether any of the points in this set lie within v
+func (set pointSet) overlaps(v *View) bool {
+	for i := set.search(v.lx); i < len(set) && set[i].X <= v.rx; i++ {
+		if set[i].Y >= v.ty && set[i].Y <= v.by {
+			return true
+		}
+	}
+	return false
+}
+
+// Indicates whether v is a single point in this set
+func (set pointSet) covers(v *View) bool {
+	return v.lx == v.rx && v.ty == v.by && set.contains(v.lx, v.ty)
+}
+
+// Returns the index of the first point in this set whose x coord is not less than x
+func (set pointSet) search(x float64) int {
+	return sort.Search(len(set), func(i int) bool {
+		return set[i].X >= x
+	})
+}
@@ -0,0 +1,174 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// A Cluster stands in for a group of nearby elements when there are too many to show individually.
+// (X,Y) is the centroid of the elements and Count is the number of them.
+// Elems holds a few of the elements as representatives of the whole cluster.
+type Cluster[E any] struct {
+	X, Y  float64
+	Count int
+	Elems []E
+}
+
+func (c Cluster[E]) String() string {
+	return fmt.Sprintf("(%d at %.3f,%.3f %v)", c.Count, c.X, c.Y, c.Elems)
+}
+
+// Returns the sums of the x and y coordinates of every element in st
+func coordSums[E any](st subtree[E]) (sx, sy float64) {
+	switch st.(type) {
+	case *leaf[E]:
+		l := st.(*leaf[E])
+		for i := range l.ps {
+			p := &l.ps[i]
+			if p.zeroed() {
+				break
+			}
+			sx += p.x * float64(len(p.elems))
+			sy += p.y * float64(len(p.elems))
+		}
+	case *node[E]:
+		n := st.(*node[E])
+		sx, sy = n.sumX, n.sumY
+	}
+	return
+}
+
+// Collects clusters of the elements within v, each covering an area no wider or taller than resolution.
+// Each cluster holds up to reps representative elements.
+type clusterer[E any] struct {
+	view       View
+	resolution float64
+	reps       int
+	clusters   []Cluster[E]
+}
+
+// Returns a new clusterer
+// Providing a resolution which isn't positive, or a negative reps, will cause a panic
+func newClusterer[E any](v *View, resolution float64, reps int) *clusterer[E] {
+	if !(resolution > 0) || reps < 0 {
+		msg := fmt.Sprintf("Cannot cluster with non-positive resolution or negative representatives. resolution : %10.3f reps : %d", resolution, reps)
+		panic(msg)
+	}
+	return &clusterer[E]{view: *v, resolution: resolution, reps: reps, clusters: make([]Cluster[E], 0)}
+}
+
+// Adds clusters for the elements of st lying within the clusterer's view
+// A node lying inside the view, and small enough, becomes a single cluster using the
+// count and coordinate sums it holds. Otherwise the node's children are clustered in turn.
+// The points in a leaf are grouped by the cell of a grid, resolution wide and high, laid
+// over the view.
+func (c *clusterer[E]) add(st subtree[E]) {
+	switch st.(type) {
+	case *leaf[E]:
+		c.addLeaf(st.(*leaf[E]))
+	case *node[E]:
+		n := st.(*node[E])
+		if n.agg.Count == 0 {
+			return
+		}
+		if c.view.covers(&n.view) && n.view.width() <= c.resolution && n.view.height() <= c.resolution {
+			cl := Cluster[E]{X: n.sumX / float64(n.agg.Count), Y: n.sumY / float64(n.agg.Count), Count: n.agg.Count}
+			n.surveyUntil(&n.view, func(_, _ float64, e E) bool {
+				if len(cl.Elems) >= c.reps {
+					return false
+				}
+				cl.Elems = append(cl.Elems, e)
+				return true
+			})
+			c.clusters = append(c.clusters, cl)
+			return
+		}
+		for i := range n.children {
+			if c.view.overlaps(n.children[i].View()) {
+				c.add(n.children[i])
+			}
+		}
+	}
+}
+
+// Adds a cluster for each grid cell holding any of l's points which lie within the view
+func (c *clusterer[E]) addLeaf(l *leaf[E]) {
+	cells := make([][2]float64, len(l.ps))
+	sums := make([][2]float64, len(l.ps))
+	found := make([]Cluster[E], len(l.ps))
+	n := 0
+	for i := range l.ps {
+		p := &l.ps[i]
+		if p.zeroed() {
+			break
+		}
+		if !c.view.contains(p.x, p.y) {
+			continue
+		}
+		cell := [2]float64{math.Floor((p.x - c.view.lx) / c.resolution), math.Floor((p.y - c.view.ty) / c.resolution)}
+		j := 0
+		for j < n && cells[j] != cell {
+			j++
+		}
+		if j == n {
+			cells[n] = cell
+			n++
+		}
+		sums[j][0] += p.x * float64(len(p.elems))
+		sums[j][1] += p.y * float64(len(p.elems))
+		found[j].Count += len(p.elems)
+		for _, e := range p.elems {
+			if len(found[j].Elems) >= c.reps {
+				break
+			}
+			found[j].Elems = append(found[j].Elems, e)
+		}
+	}
+	for j := 0; j < n; j++ {
+		found[j].X = sums[j][0] / float64(found[j].Count)
+		found[j].Y = sums[j][1] / float64(found[j].Count)
+		c.clusters = append(c.clusters, found[j])
+	}
+}
+
+// Adds a cluster for each grid cell holding any of the elements surveyed by survey
+// survey is called once, with the clusterer's view, and must apply fun to every element within it.
+// Used by indexes which, unlike a quadtree, hold no coordinate sums to cluster whole areas with.
+func (c *clusterer[E]) addSurvey(survey func(reg Region, fun func(x, y float64, e E))) {
+	cells := make(map[[2]float64]int)
+	var sums [][2]float64
+	var found []Cluster[E]
+	survey(&c.view, func(x, y float64, e E) {
+		cell := [2]float64{math.Floor((x - c.view.lx) / c.resolution), math.Floor((y - c.view.ty) / c.resolution)}
+		j, ok := cells[cell]
+		if !ok {
+			j = len(found)
+			cells[cell] = j
+			sums = append(sums, [2]float64{})
+			found = append(found, Cluster[E]{})
+		}
+		sums[j][0] += x
+		sums[j][1] += y
+		found[j].Count++
+		if len(found[j].Elems) < c.reps {
+			found[j].Elems = append(found[j].Elems, e)
+		}
+	})
+	for j := range found {
+		found[j].X = sums[j][0] / float64(found[j].Count)
+		found[j].Y = sums[j][1] / float64(found[j].Count)
+		c.clusters = append(c.clusters, found[j])
+	}
+}
+
+// Returns clusters of the elements lying within v, each covering an area no wider or taller than resolution
+// Each cluster holds its centroid, the number of elements in it and up to reps of those elements.
+// Clusters follow the subdivisions of the tree, any node small enough is made into a
+// single cluster without visiting its elements. So clusters are not evenly spaced,
+// and two clusters may lie closer together than resolution.
+// Providing a resolution which isn't positive, or a negative reps, will cause a panic
+func (r *QuadTree[E]) Clusters(v *View, resolution float64, reps int) []Cluster[E] {
+	c := newClusterer[E](v, resolution, reps)
+	c.add(r.rootNode)
+	return c.clusters
+}
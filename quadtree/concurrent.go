@@ -0,0 +1,301 @@
+package quadtree
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"sync"
+)
+
+// The number of times the view of a concurrent quadtree is quartered to produce its stripes.
+// A depth of 2 produces 16 independently locked stripes.
+const stripeDepth = 2
+
+// A stripe is an independent quadtree covering one section of a concurrent quadtree's view.
+// Each stripe is guarded by its own read/write lock.
+type stripe struct {
+	sync.RWMutex
+	tree *QuadTree[interface{}]
+}
+
+// A ctree implements the public interface T and is safe for concurrent use.
+// The view of a ctree is divided into a fixed grid of stripes. Operations lock only
+// the stripes whose views overlap the region they operate on, a read lock for
+// Survey and Nearest, and a write lock for Insert, Del and Move. So any number
+// of goroutines may survey in parallel, and inserts and deletes proceed in
+// parallel with operations on other stripes.
+// Operations spanning several stripes lock each stripe in turn, not all at once,
+// so they are not atomic with respect to each other.
+// The functions passed to Survey, Del and Nearest are called while a stripe is locked
+// and must not call back into the same tree.
+// A ctree is always Bounded, its stripes are fixed when it is created.
+type ctree struct {
+	view    View
+	stripes []stripe
+}
+
+// Returns a new empty QuadTree, safe for concurrent use, whose View extends from
+// leftX to rightX across the x axis and
+// topY down to bottomY along the y axis
+// leftX < rightX
+// topY < bottomY
+// leafAllocation is shared evenly between each of the tree's stripes
+func NewConcurrentQuadTree(leftX, rightX, topY, bottomY float64, leafAllocation int64) T {
+	view := NewViewP(leftX, rightX, topY, bottomY)
+	vs := stripeViews(view, stripeDepth)
+	ct := &ctree{view: *view, stripes: make([]stripe, len(vs))}
+	for i := range vs {
+		ct.stripes[i].tree = newRoot[interface{}](vs[i], leafAllocation/int64(len(vs)))
+	}
+	return ct
+}
+
+// Returns the views produced by quartering v, and each of its quarters, depth times
+func stripeViews(v *View, depth int) []*View {
+	if depth == 0 {
+		return []*View{v}
+	}
+	v1, v2, v3, v4 := v.quarters()
+	vs := make([]*View, 0, 16)
+	for _, q := range []*View{v1, v2, v3, v4} {
+		vs = append(vs, stripeViews(q, depth-1)...)
+	}
+	return vs
+}
+
+// Returns the View for this tree
+func (ct *ctree) View() *View {
+	return &ct.view
+}
+
+// Returns the index of the stripe which stores elements at (x,y)
+// Points lying on the border between stripes belong to the first such stripe.
+// Returns -1 if (x,y) lies outside this tree.
+func (ct *ctree) stripeFor(x, y float64) int {
+	for i := range ct.stripes {
+		if ct.stripes[i].tree.View().contains(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Inserts e into the single stripe which stores elements at (x,y)
+// Returns an error if (x,y) lies outside this tree
+func (ct *ctree) Insert(x, y float64, e interface{}) error {
+	i := ct.stripeFor(x, y)
+	if i < 0 {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, x, y, &ct.view)
+	}
+	s := &ct.stripes[i]
+	s.Lock()
+	defer s.Unlock()
+	return s.tree.Insert(x, y, e)
+}
+
+// Applies fun to every element occurring within any view in vs in this tree
+func (ct *ctree) Survey(vs []*View, fun func(x, y float64, e interface{})) {
+	ct.SurveyRegion(views(vs), fun)
+}
+
+// Applies fun to every element occurring within reg in this tree
+func (ct *ctree) SurveyRegion(reg Region, fun func(x, y float64, e interface{})) {
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		if reg.overlaps(s.tree.View()) {
+			s.RLock()
+			s.tree.SurveyRegion(reg, fun)
+			s.RUnlock()
+		}
+	}
+}
+
+// Applies fun to every element occurring within reg in this tree, stopping as soon as fun returns false
+func (ct *ctree) SurveyUntil(reg Region, fun func(x, y float64, e interface{}) bool) {
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		if reg.overlaps(s.tree.View()) {
+			s.RLock()
+			more := s.tree.rootNode.surveyUntil(reg, fun)
+			s.RUnlock()
+			if !more {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the location of, and each element occurring within, reg in this tree
+// Each stripe is read locked while it is being iterated over, so the loop body must not modify the tree.
+func (ct *ctree) Within(reg Region) iter.Seq2[Point, interface{}] {
+	return func(yield func(Point, interface{}) bool) {
+		ct.SurveyUntil(reg, func(x, y float64, e interface{}) bool {
+			return yield(Point{x, y}, e)
+		})
+	}
+}
+
+// Returns the number of elements occurring within any view in vs in this tree
+func (ct *ctree) Count(vs []*View) int {
+	return ct.CountRegion(views(vs))
+}
+
+// Returns the number of elements occurring within reg in this tree
+func (ct *ctree) CountRegion(reg Region) int {
+	count := 0
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		if reg.overlaps(s.tree.View()) {
+			s.RLock()
+			count += s.tree.CountRegion(reg)
+			s.RUnlock()
+		}
+	}
+	return count
+}
+
+// Returns the number of elements lying within each cell of a grid of xCells by yCells laid over v
+func (ct *ctree) Heatmap(v *View, xCells, yCells int) [][]int {
+	h := newHeatmap(v, xCells, yCells)
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		if v.overlaps(s.tree.View()) {
+			s.RLock()
+			heatmapAdd(h, s.tree.rootNode)
+			s.RUnlock()
+		}
+	}
+	return h.counts
+}
+
+// Returns clusters of the elements lying within v, each no wider or taller than resolution
+// Each stripe is clustered separately, so no cluster spans two stripes.
+func (ct *ctree) Clusters(v *View, resolution float64, reps int) []Cluster[interface{}] {
+	c := newClusterer[interface{}](v, resolution, reps)
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		if v.overlaps(s.tree.View()) {
+			s.RLock()
+			c.add(s.tree.rootNode)
+			s.RUnlock()
+		}
+	}
+	return c.clusters
+}
+
+// Dels each element, e, in this tree which lies within reg and for which pred(e) returns true
+func (ct *ctree) Del(reg Region, pred func(x, y float64, e interface{}) bool) {
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		if reg.overlaps(s.tree.View()) {
+			s.Lock()
+			s.tree.Del(reg, pred)
+			s.Unlock()
+		}
+	}
+}
+
+// Moves each element at (oldX,oldY) for which pred(e) returns true to (newX,newY)
+// If the two points lie in different stripes both stripes are locked, in the order
+// they appear in the tree, so that no other operation can see the elements in both
+// places, or in neither.
+// Returns an error, having moved nothing, if (newX,newY) lies outside this tree
+func (ct *ctree) Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e interface{}) bool) error {
+	oi := ct.stripeFor(oldX, oldY)
+	ni := ct.stripeFor(newX, newY)
+	if ni < 0 {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, newX, newY, &ct.view)
+	}
+	if oi < 0 {
+		return nil
+	}
+	if oi == ni {
+		s := &ct.stripes[oi]
+		s.Lock()
+		defer s.Unlock()
+		return s.tree.Move(oldX, oldY, newX, newY, pred)
+	}
+	first, second := oi, ni
+	if second < first {
+		first, second = second, first
+	}
+	ct.stripes[first].Lock()
+	defer ct.stripes[first].Unlock()
+	ct.stripes[second].Lock()
+	defer ct.stripes[second].Unlock()
+	moved := make([]interface{}, 0, 1)
+	collect := func(x, y float64, e interface{}) bool {
+		if pred(x, y, e) {
+			moved = append(moved, e)
+			return true
+		}
+		return false
+	}
+	ct.stripes[oi].tree.Del(PointViewP(oldX, oldY), collect)
+	for _, e := range moved {
+		ct.stripes[ni].tree.Insert(newX, newY, e)
+	}
+	return nil
+}
+
+// Returns up to k elements ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil
+// Stripes are searched in order of their distance from (x,y), and the search stops
+// once k elements have been found nearer than the next stripe.
+func (ct *ctree) Nearest(x, y float64, k int, filter func(x, y float64, e interface{}) bool) []interface{} {
+	if k <= 0 {
+		return []interface{}{}
+	}
+	order := make([]*stripe, len(ct.stripes))
+	for i := range ct.stripes {
+		order[i] = &ct.stripes[i]
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return order[i].tree.View().distSq(x, y) < order[j].tree.View().distSq(x, y)
+	})
+	found := make([]candidate[interface{}], 0, k)
+	for _, s := range order {
+		if len(found) >= k && s.tree.View().distSq(x, y) > found[k-1].distSq {
+			break
+		}
+		s.RLock()
+		found = append(found, nearestIn([]subtree[interface{}]{s.tree.rootNode}, x, y, k, filter)...)
+		s.RUnlock()
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].distSq < found[j].distSq
+		})
+		if len(found) > k {
+			found = found[:k]
+		}
+	}
+	return candidateElems(found)
+}
+
+// Returns a snapshot of this tree as it is now
+// Every stripe is write locked, in order, before any is snapshotted, so the
+// snapshot is a single point in time across the whole tree.
+func (ct *ctree) Snapshot() *Snapshot[interface{}] {
+	for i := range ct.stripes {
+		ct.stripes[i].Lock()
+	}
+	snap := &Snapshot[interface{}]{view: ct.view, roots: make([]subtree[interface{}], len(ct.stripes))}
+	for i := range ct.stripes {
+		snap.roots[i] = ct.stripes[i].tree.Snapshot().roots[0]
+	}
+	for i := range ct.stripes {
+		ct.stripes[i].Unlock()
+	}
+	return snap
+}
+
+// Returns a human friendly string representation of each stripe of this tree
+func (ct *ctree) String() string {
+	str := ""
+	for i := range ct.stripes {
+		s := &ct.stripes[i]
+		s.RLock()
+		str += s.tree.String() + "\n"
+		s.RUnlock()
+	}
+	return str
+}
@@ -1,15 +1,77 @@
 package quadtree
 
+import (
+	"iter"
+)
+
 // Public interface for quadtrees.
 type T interface {
 	View() *View
 	// Inserts e into this quadtree at point (x,y)
-	Insert(x, y float64, e interface{})
+	// Returns an error if (x,y) lies outside this quadtree and it cannot grow to contain it
+	Insert(x, y float64, e interface{}) error
 	// Applies fun to every element in this quadtree that lies within any view in views
 	Survey(views []*View, fun func(x, y float64, e interface{}))
-	// Applies pred to every element in this quadtree that lies within any view in views
+	// Applies fun to every element in this quadtree that lies within reg
+	SurveyRegion(reg Region, fun func(x, y float64, e interface{}))
+	// Applies fun to every element in this quadtree that lies within reg, until fun returns false
+	SurveyUntil(reg Region, fun func(x, y float64, e interface{}) bool)
+	// Returns the number of elements in this quadtree that lie within any view in views
+	Count(views []*View) int
+	// Returns the number of elements in this quadtree that lie within reg
+	CountRegion(reg Region) int
+	// Returns the number of elements in this quadtree lying within each cell of a grid of xCells by yCells laid over v
+	Heatmap(v *View, xCells, yCells int) [][]int
+	// Returns clusters of the elements in this quadtree lying within v, each no wider or taller than resolution
+	// Each cluster holds up to reps of its elements
+	Clusters(v *View, resolution float64, reps int) []Cluster[interface{}]
+	// Returns an iterator over every element in this quadtree that lies within reg, and its location
+	Within(reg Region) iter.Seq2[Point, interface{}]
+	// Applies pred to every element in this quadtree that lies within reg
 	// If pred returns true that element is removed
-	Del(views *View, pred func(x, y float64, e interface{}) bool)
+	Del(reg Region, pred func(x, y float64, e interface{}) bool)
+	// Moves every element at (oldX,oldY) for which pred returns true to (newX,newY)
+	// Returns an error, having moved nothing, if (newX,newY) lies outside this quadtree
+	// and it cannot grow to contain it
+	Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e interface{}) bool) error
+	// Returns up to k elements ordered by their distance from (x,y), nearest first
+	// Only elements for which filter returns true are returned, filter may be nil
+	Nearest(x, y float64, k int, filter func(x, y float64, e interface{}) bool) []interface{}
+	// Returns a read-only, point-in-time, copy of this quadtree which is safe to read
+	// from any goroutine while this quadtree carries on being modified
+	Snapshot() *Snapshot[interface{}]
 	// Provides a human readable (as far as possible) string representation of this tree
 	String() string
 }
+
+// The operations shared by every index storing elements of type E.
+// Each of QuadTree, HashGrid, ZOrderIndex and Keyed implements Index, and the methods
+// behave as described for T.
+type Index[E any] interface {
+	View() *View
+	Insert(x, y float64, e E) error
+	Survey(views []*View, fun func(x, y float64, e E))
+	SurveyRegion(reg Region, fun func(x, y float64, e E))
+	SurveyUntil(reg Region, fun func(x, y float64, e E) bool)
+	Count(views []*View) int
+	CountRegion(reg Region) int
+	Heatmap(v *View, xCells, yCells int) [][]int
+	Clusters(v *View, resolution float64, reps int) []Cluster[E]
+	Within(reg Region) iter.Seq2[Point, E]
+	Del(reg Region, pred func(x, y float64, e E) bool)
+	Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e E) bool) error
+	Nearest(x, y float64, k int, filter func(x, y float64, e E) bool) []E
+	Snapshot() *Snapshot[E]
+	String() string
+}
+
+// A point on the plane at (X,Y)
+type Point struct {
+	X, Y float64
+}
+
+// An element to be stored in a quadtree at the point (X,Y)
+type Entry[E any] struct {
+	X, Y float64
+	Elem E
+}
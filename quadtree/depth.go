@@ -0,0 +1,75 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// The depth below which a new tree's leaves are never divided, see SetDepthLimits.
+// Each quartering halves the width and height of a view, so leaves this deep are
+// about one four-billionth of the width and height of the tree.
+const DefaultMaxDepth = 32
+
+// Sets the limits beyond which a full leaf overflows rather than being divided
+// A leaf maxDepth quarterings below the root, or whose quarters would be narrower or
+// shorter than minCellSize, holds every point inserted into it however many there are.
+// This stops crowds of points packed very close together from building long chains
+// of nodes, quartering views until floating point precision runs out.
+// A leaf is never divided once quartering its view no longer produces smaller views.
+// A new tree has a maxDepth of DefaultMaxDepth and a minCellSize of zero.
+// The limits apply to leaves divided from now on, leaves already divided are unaffected.
+// Providing a maxDepth less than one, or a negative minCellSize, will cause a panic
+func (r *QuadTree[E]) SetDepthLimits(maxDepth int, minCellSize float64) {
+	if maxDepth < 1 || !(minCellSize >= 0) {
+		msg := fmt.Sprintf("Cannot limit depth to less than one or cell size to less than zero. maxDepth : %d minCellSize : %10.3f", maxDepth, minCellSize)
+		panic(msg)
+	}
+	r.maxDepth = maxDepth
+	r.minCell = minCellSize
+}
+
+// Returns the number of quarterings from this tree's root down to a subtree with view v
+// While every leaf has been divided at its midpoint the depth follows from how much
+// smaller v is than the root's view. Once any leaf has been divided at the median, under
+// any split strategy since, the subtree is found by descending from the root through the
+// first child covering v at each node.
+func (r *QuadTree[E]) depthOf(v *View) int {
+	rv := r.rootNode.View()
+	if !r.uneven {
+		return int(math.Round(math.Log2(math.Max(rv.width()/v.width(), rv.height()/v.height()))))
+	}
+	depth := 0
+	st := r.rootNode
+	for !st.View().eq(v) {
+		n, ok := st.(*node[E])
+		if !ok {
+			break
+		}
+		next := st
+		for i := range n.children {
+			if n.children[i].View().covers(v) {
+				next = n.children[i]
+				break
+			}
+		}
+		if next == st {
+			break
+		}
+		st = next
+		depth++
+	}
+	return depth
+}
+
+// Indicates whether a full leaf with view v may be divided into four quarters
+func (r *QuadTree[E]) canDivide(v *View) bool {
+	midx := v.lx + (v.rx-v.lx)/2
+	midy := v.ty + (v.by-v.ty)/2
+	if !(v.lx < midx && midx < v.rx && v.ty < midy && midy < v.by) {
+		return false
+	}
+	if v.width()/2 < r.minCell || v.height()/2 < r.minCell {
+		return false
+	}
+	return r.depthOf(v) < r.maxDepth
+}
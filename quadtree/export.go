@@ -0,0 +1,128 @@
+package quadtree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// A location at which one or more elements are stored
+type location struct {
+	x, y  float64
+	elems int
+}
+
+// Returns each distinct location among entries, in which elements sharing a location are adjacent
+func locations[E any](entries []Entry[E]) []location {
+	var locs []location
+	for _, e := range entries {
+		if last := len(locs) - 1; last >= 0 && locs[last].x == e.X && locs[last].y == e.Y {
+			locs[last].elems++
+			continue
+		}
+		locs = append(locs, location{e.X, e.Y, 1})
+	}
+	return locs
+}
+
+// The style sheet of an SVG drawing of a quadtree
+const svgStyle = `.node{fill:none;stroke:#999;stroke-width:1}` +
+	`.leaf{fill:none;stroke:#36c;stroke-width:0.5}` +
+	`.overflow{fill:#c003;stroke:#c00;stroke-width:0.5}` +
+	`.point{fill:#000}`
+
+// Writes an SVG drawing of the nodes, leaves and points of t to w
+// The drawing is width pixels wide, and as high as keeps the proportions of t's View.
+// It is laid out like a View, with x increasing to the right and y increasing downwards.
+// Nodes are outlined in grey and leaves in blue, leaves holding more than LEAF_SIZE
+// points, see SetDepthLimits, are filled in red. Each distinct location is a black dot.
+// Returns the first error met writing to w
+func WriteSVG[E any](w io.Writer, t Walker[E], width float64) error {
+	v := t.View()
+	scale := 1.0
+	if v.width() > 0 {
+		scale = width / v.width()
+	}
+	height := v.height() * scale
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%.2f" height="%.2f" viewBox="0 0 %.2f %.2f">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, "<style>%s</style>\n", svgStyle)
+	rect := func(class string, rv *View) {
+		fmt.Fprintf(bw, `<rect class="%s" x="%.2f" y="%.2f" width="%.2f" height="%.2f"/>`+"\n",
+			class, (rv.lx-v.lx)*scale, (rv.ty-v.ty)*scale, rv.width()*scale, rv.height()*scale)
+	}
+	t.Walk(func(tn *TreeNode[E]) bool {
+		if !tn.Leaf {
+			rect("node", &tn.View)
+			return true
+		}
+		locs := locations(tn.Entries)
+		if len(locs) > LEAF_SIZE {
+			rect("overflow", &tn.View)
+		} else {
+			rect("leaf", &tn.View)
+		}
+		for _, loc := range locs {
+			fmt.Fprintf(bw, `<circle class="point" cx="%.2f" cy="%.2f" r="1.5"><title>%d elements</title></circle>`+"\n",
+				(loc.x-v.lx)*scale, (loc.y-v.ty)*scale, loc.elems)
+		}
+		return true
+	})
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// The GeoJSON representation of a feature, see RFC 7946
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// The GeoJSON representation of a geometry, Coordinates are [longitude, latitude]
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// Returns the GeoJSON polygon outlining gv, whose x is the latitude and y the longitude
+// The ring runs anticlockwise, from the south west corner, as RFC 7946 requires.
+func geoJSONPolygon(gv *View) geoJSONGeometry {
+	ring := [][2]float64{{gv.ty, gv.lx}, {gv.by, gv.lx}, {gv.by, gv.rx}, {gv.ty, gv.rx}, {gv.ty, gv.lx}}
+	return geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]float64{ring}}
+}
+
+// Writes the nodes, leaves and points of t to w as a GeoJSON FeatureCollection
+// t is expected to store points with x as the latitude and y as the longitude, both
+// in degrees, like a GeoView.
+// Each node and leaf is a Polygon feature, with the properties "kind", either "node"
+// or "leaf", and "depth". A leaf also has the property "points", the number of distinct
+// locations in it. Each location is a Point feature with the property "elems", the
+// number of elements stored there.
+// Returns the first error met writing to w
+func WriteGeoJSON[E any](w io.Writer, t Walker[E]) error {
+	features := make([]geoJSONFeature, 0)
+	t.Walk(func(tn *TreeNode[E]) bool {
+		if !tn.Leaf {
+			features = append(features, geoJSONFeature{"Feature", geoJSONPolygon(&tn.View), map[string]interface{}{"kind": "node", "depth": tn.Depth}})
+			return true
+		}
+		locs := locations(tn.Entries)
+		features = append(features, geoJSONFeature{"Feature", geoJSONPolygon(&tn.View), map[string]interface{}{"kind": "leaf", "depth": tn.Depth, "points": len(locs)}})
+		for _, loc := range locs {
+			point := geoJSONGeometry{Type: "Point", Coordinates: [2]float64{loc.y, loc.x}}
+			features = append(features, geoJSONFeature{"Feature", point, map[string]interface{}{"elems": loc.elems}})
+		}
+		return true
+	})
+	collection := struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}{"FeatureCollection", features}
+	bw := bufio.NewWriter(w)
+	if err := json.NewEncoder(bw).Encode(collection); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,189 @@
+package quadtree
+
+import (
+	"fmt"
+)
+
+// The deepest an ExtentTree will divide its nodes.
+// Beyond this depth a node holds every element it is given, however many,
+// so that many small extents piled on a single spot can't divide the tree forever.
+const maxExtentDepth = 32
+
+// An extent is an element stored along with the rectangular area it occupies
+type extent[E any] struct {
+	view View
+	elem E
+}
+
+// An extentNode covers a quarter of its parent's view.
+// Each element is stored in the deepest node whose view covers the element's
+// entire extent. So an element straddling the border between two children
+// stays in the parent, however small it is.
+// A node without children holds up to LEAF_SIZE elements before it is divided,
+// unless it is already maxExtentDepth deep.
+type extentNode[E any] struct {
+	view     View
+	depth    int
+	elems    []extent[E]
+	children *[4]*extentNode[E]
+}
+
+// An ExtentTree stores elements of type E, each occupying a rectangular View
+// rather than a single point. Venues, geofences or the accuracy circle of a
+// location can be stored and then found by any query which touches them.
+// An ExtentTree is always Bounded, every extent must lie within the tree's View.
+// An ExtentTree does not support concurrent access.
+type ExtentTree[E any] struct {
+	root *extentNode[E]
+	size int
+}
+
+// Returns a new empty ExtentTree, storing elements of type E, whose View extends from
+// leftX to rightX across the x axis and
+// topY down to bottomY along the y axis
+// leftX < rightX
+// topY < bottomY
+func NewExtentTree[E any](leftX, rightX, topY, bottomY float64) *ExtentTree[E] {
+	return &ExtentTree[E]{root: &extentNode[E]{view: NewView(leftX, rightX, topY, bottomY)}}
+}
+
+// Inserts e, occupying the area v, into this tree
+// Returns an error, and e is not inserted, if v doesn't lie entirely within this tree's View
+func (et *ExtentTree[E]) Insert(v *View, e E) error {
+	if !et.root.view.covers(v) {
+		return fmt.Errorf("%w: %v outside %v", OutOfBoundsErr, v, &et.root.view)
+	}
+	et.root.insert(extent[E]{*v, e})
+	et.size++
+	return nil
+}
+
+// Applies fun to every element whose extent overlaps any view in vs
+func (et *ExtentTree[E]) Survey(vs []*View, fun func(v *View, e E)) {
+	et.root.survey(views(vs), fun)
+}
+
+// Applies fun to every element whose extent overlaps reg
+// Elements are chosen by reg's overlap test, so a Region which reports overlaps
+// conservatively, such as a GeoCircle, may include elements lying just outside it.
+func (et *ExtentTree[E]) SurveyRegion(reg Region, fun func(v *View, e E)) {
+	et.root.survey(reg, fun)
+}
+
+// Dels each element, e, in this tree whose extent overlaps reg and for which pred(e) returns true
+func (et *ExtentTree[E]) Del(reg Region, pred func(v *View, e E) bool) {
+	et.size -= et.root.del(reg, pred)
+}
+
+// Returns the number of elements stored in this tree
+func (et *ExtentTree[E]) Len() int {
+	return et.size
+}
+
+// Returns the View for this tree
+func (et *ExtentTree[E]) View() *View {
+	return &et.root.view
+}
+
+// Returns a human friendly string representation of this tree
+func (et *ExtentTree[E]) String() string {
+	return et.root.String()
+}
+
+// Stores ext in the deepest node under n which covers it
+func (n *extentNode[E]) insert(ext extent[E]) {
+	if n.children != nil {
+		for _, child := range n.children {
+			if child.view.covers(&ext.view) {
+				child.insert(ext)
+				return
+			}
+		}
+		n.elems = append(n.elems, ext)
+		return
+	}
+	n.elems = append(n.elems, ext)
+	if len(n.elems) > LEAF_SIZE && n.depth < maxExtentDepth {
+		n.divide()
+	}
+}
+
+// Creates four children for n and pushes down every element which fits inside one of them
+func (n *extentNode[E]) divide() {
+	v1, v2, v3, v4 := n.view.quarters()
+	n.children = new([4]*extentNode[E])
+	for i, v := range []*View{v1, v2, v3, v4} {
+		n.children[i] = &extentNode[E]{view: *v, depth: n.depth + 1}
+	}
+	elems := n.elems
+	n.elems = nil
+	for _, ext := range elems {
+		n.insert(ext)
+	}
+}
+
+// Applies fun to every element under n whose extent overlaps reg
+func (n *extentNode[E]) survey(reg Region, fun func(v *View, e E)) {
+	for i := range n.elems {
+		ext := &n.elems[i]
+		if reg.overlaps(&ext.view) {
+			fun(&ext.view, ext.elem)
+		}
+	}
+	if n.children != nil {
+		for _, child := range n.children {
+			if reg.overlaps(&child.view) {
+				child.survey(reg, fun)
+			}
+		}
+	}
+}
+
+// Dels each element under n whose extent overlaps reg and for which pred(e) returns true
+// If every child of n is left empty the children are discarded.
+// Returns the number of elements deleted.
+func (n *extentNode[E]) del(reg Region, pred func(v *View, e E) bool) int {
+	deleted := 0
+	for i := len(n.elems) - 1; i >= 0; i-- {
+		ext := &n.elems[i]
+		if reg.overlaps(&ext.view) && pred(&ext.view, ext.elem) {
+			last := len(n.elems) - 1
+			n.elems[i] = n.elems[last]
+			n.elems[last] = extent[E]{}
+			n.elems = n.elems[:last]
+			deleted++
+		}
+	}
+	if n.children != nil {
+		allEmpty := true
+		for _, child := range n.children {
+			if reg.overlaps(&child.view) {
+				deleted += child.del(reg, pred)
+			}
+			allEmpty = allEmpty && child.isEmpty()
+		}
+		if allEmpty {
+			n.children = nil
+		}
+	}
+	return deleted
+}
+
+// Indicates whether n holds no elements and has no children
+func (n *extentNode[E]) isEmpty() bool {
+	return len(n.elems) == 0 && n.children == nil
+}
+
+// Returns a human friendly string representing this node, including its children.
+func (n *extentNode[E]) String() string {
+	str := "<" + n.view.String()
+	for i := range n.elems {
+		str += fmt.Sprintf("(%v,%v)", n.elems[i].elem, &n.elems[i].view)
+	}
+	if n.children != nil {
+		for _, child := range n.children {
+			str += "-\n" + child.String()
+		}
+	}
+	return str + ">"
+}
@@ -0,0 +1,112 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// A GeoView is a rectangle of latitude and longitude on the earth's surface.
+// A quadtree queried with a GeoView is expected to store points with
+// x as the latitude and y as the longitude, both in degrees, in a View
+// extending from -90 to 90 and -180 to 180.
+// A GeoView crossing the antimeridian, or passing over a pole, is split into
+// the sub-rectangles of that View which it covers.
+type GeoView struct {
+	views []*View
+}
+
+// Returns a new GeoView extending from south to north and from west to east, all in degrees
+// A view whose west is greater than its east crosses the antimeridian, e.g. west 170 east -170.
+// Longitudes outside -180 to 180 are wrapped around the globe.
+// A latitude beyond a pole continues down the other side of the globe, 180 degrees of longitude away.
+// Providing a north less than south will cause a panic
+func NewGeoView(south, north, west, east float64) *GeoView {
+	if north < south {
+		msg := fmt.Sprintf("Cannot create geo view with inverted latitudes. south : %10.3f north : %10.3f", south, north)
+		panic(msg)
+	}
+	vs := make([]*View, 0, 4)
+	// A view lying entirely beyond a pole has no part on this side of the globe
+	if south <= 90 && north >= -90 {
+		for _, s := range lngSpans(west, east) {
+			vs = append(vs, NewViewP(math.Max(south, -90), math.Min(north, 90), s[0], s[1]))
+		}
+	}
+	// Beyond a pole the view continues on the opposite side of the globe
+	if north > 90 {
+		for _, s := range lngSpans(west+180, east+180) {
+			vs = append(vs, NewViewP(math.Max(180-north, -90), math.Min(180-south, 90), s[0], s[1]))
+		}
+	}
+	if south < -90 {
+		for _, s := range lngSpans(west+180, east+180) {
+			vs = append(vs, NewViewP(math.Max(-180-north, -90), math.Min(-180-south, 90), s[0], s[1]))
+		}
+	}
+	return &GeoView{vs}
+}
+
+// Returns a new GeoView bounding the circle of great-circle radius metres centred at (lat,lng)
+// If the circle covers a pole every longitude is included.
+// Providing a negative distance will cause a panic
+func NewGeoViewAround(lat, lng, metres float64) *GeoView {
+	if metres < 0 {
+		msg := fmt.Sprintf("Cannot create geo view with negative radius. metres : %10.3f", metres)
+		panic(msg)
+	}
+	return &GeoView{geoBounds(lat, wrapLng(lng), metres)}
+}
+
+// Returns the sub-rectangles of this GeoView, each lying within -90 to 90 and -180 to 180
+func (gv *GeoView) Views() []*View {
+	return gv.views
+}
+
+// Indicates whether this GeoView contains the point (lat,lng)
+func (gv *GeoView) contains(lat, lng float64) bool {
+	return contains(gv.views, lat, lng)
+}
+
+// Indicates whether any part of this GeoView lies within v
+func (gv *GeoView) overlaps(v *View) bool {
+	return overlaps(gv.views, v)
+}
+
+// Indicates whether all of v lies within a single sub-rectangle of this GeoView
+func (gv *GeoView) covers(v *View) bool {
+	return covers(gv.views, v)
+}
+
+func (gv *GeoView) String() string {
+	str := ""
+	for _, v := range gv.views {
+		str += v.String()
+	}
+	return str
+}
+
+// Returns the spans of longitude, each lying within -180 to 180, running east from west to east
+// A span crossing the antimeridian is split in two.
+func lngSpans(west, east float64) [][2]float64 {
+	if east-west >= 360 {
+		return [][2]float64{{-180, 180}}
+	}
+	w, e := wrapLng(west), wrapLng(east)
+	if w <= e {
+		return [][2]float64{{w, e}}
+	}
+	return [][2]float64{{w, 180}, {-180, e}}
+}
+
+// Returns lng wrapped around the globe to lie within -180 to 180
+// Longitudes already in that range are returned unchanged.
+func wrapLng(lng float64) float64 {
+	if lng >= -180 && lng <= 180 {
+		return lng
+	}
+	w := math.Mod(lng+180, 360)
+	if w < 0 {
+		w += 360
+	}
+	return w - 180
+}
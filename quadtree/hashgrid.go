@@ -0,0 +1,311 @@
+package quadtree
+
+import (
+	"fmt"
+	"iter"
+	"math"
+)
+
+// A HashGrid is a spatial index dividing its view into a uniform grid of cells.
+// Each point hashes directly to the cell containing it, so inserts and deletes never
+// restructure anything, but a crowded cell is scanned in full by every survey
+// touching it. When E is interface{} a HashGrid implements the public interface T.
+// A HashGrid is always Bounded, its cells are fixed when it is created.
+// Surveys visit the cells overlapping a region by repeatedly halving the block of
+// cells under consideration, pruning blocks which don't overlap the region.
+type HashGrid[E any] struct {
+	view       View
+	cols, rows int
+	cellW      float64
+	cellH      float64
+	// The points in the cell at column c and row r are at cells[c*rows+r]
+	cells [][]vpoint[E]
+	// The number of elements in each cell, indexed like cells
+	sizes []int
+	size  int
+}
+
+// Returns a new empty HashGrid, storing elements of type E, whose View extends from
+// leftX to rightX across the x axis and
+// topY down to bottomY along the y axis
+// leftX < rightX
+// topY < bottomY
+// The view is divided into cols columns across the x axis and rows rows along the y axis.
+// Providing fewer than one column or row will cause a panic
+func NewHashGrid[E any](leftX, rightX, topY, bottomY float64, cols, rows int) *HashGrid[E] {
+	if cols < 1 || rows < 1 {
+		msg := fmt.Sprintf("Cannot create hash grid with fewer than one cell. cols : %d rows : %d", cols, rows)
+		panic(msg)
+	}
+	v := NewView(leftX, rightX, topY, bottomY)
+	return &HashGrid[E]{
+		view:  v,
+		cols:  cols,
+		rows:  rows,
+		cellW: v.width() / float64(cols),
+		cellH: v.height() / float64(rows),
+		cells: make([][]vpoint[E], cols*rows),
+		sizes: make([]int, cols*rows),
+	}
+}
+
+// Returns the x coordinate of the left edge of column c, c == g.cols gives the right edge of the grid
+func (g *HashGrid[E]) colEdge(c int) float64 {
+	if c >= g.cols {
+		return g.view.rx
+	}
+	return g.view.lx + float64(c)*g.cellW
+}
+
+// Returns the y coordinate of the top edge of row r, r == g.rows gives the bottom edge of the grid
+func (g *HashGrid[E]) rowEdge(r int) float64 {
+	if r >= g.rows {
+		return g.view.by
+	}
+	return g.view.ty + float64(r)*g.cellH
+}
+
+// Returns the index of the cell containing (x,y), which must lie within this grid's view
+// A point lying on the border between cells belongs to the cell to its right or below.
+// The cell is checked against the same edges used to build cell views, so the view of
+// a cell always contains every point in it.
+func (g *HashGrid[E]) cell(x, y float64) int {
+	c := min(max(int((x-g.view.lx)/g.cellW), 0), g.cols-1)
+	for c > 0 && x < g.colEdge(c) {
+		c--
+	}
+	for c < g.cols-1 && x >= g.colEdge(c+1) {
+		c++
+	}
+	r := min(max(int((y-g.view.ty)/g.cellH), 0), g.rows-1)
+	for r > 0 && y < g.rowEdge(r) {
+		r--
+	}
+	for r < g.rows-1 && y >= g.rowEdge(r+1) {
+		r++
+	}
+	return c*g.rows + r
+}
+
+// Returns the view covering columns c0 up to c1, and rows r0 up to r1, exclusive
+func (g *HashGrid[E]) blockView(c0, c1, r0, r1 int) *View {
+	return NewViewP(g.colEdge(c0), g.colEdge(c1), g.rowEdge(r0), g.rowEdge(r1))
+}
+
+// Calls fun on each cell, in the block of columns c0 up to c1 and rows r0 up to r1,
+// whose view overlaps reg. fun is given the cell's index and view.
+// Blocks not overlapping reg are skipped whole, the rest are halved along their longer side.
+// Stops as soon as fun returns false.
+// Returns false if fun returned false, true otherwise.
+func (g *HashGrid[E]) visit(reg Region, c0, c1, r0, r1 int, fun func(i int, v *View) bool) bool {
+	v := g.blockView(c0, c1, r0, r1)
+	if !reg.overlaps(v) {
+		return true
+	}
+	if c1-c0 == 1 && r1-r0 == 1 {
+		return fun(c0*g.rows+r0, v)
+	}
+	if c1-c0 >= r1-r0 {
+		mid := c0 + (c1-c0)/2
+		return g.visit(reg, c0, mid, r0, r1, fun) && g.visit(reg, mid, c1, r0, r1, fun)
+	}
+	mid := r0 + (r1-r0)/2
+	return g.visit(reg, c0, c1, r0, mid, fun) && g.visit(reg, c0, c1, mid, r1, fun)
+}
+
+// Calls fun on each cell whose view overlaps reg, see visit
+func (g *HashGrid[E]) visitAll(reg Region, fun func(i int, v *View) bool) bool {
+	return g.visit(reg, 0, g.cols, 0, g.rows, fun)
+}
+
+// Returns the View for this grid
+func (g *HashGrid[E]) View() *View {
+	return &g.view
+}
+
+// Inserts e into this grid at (x,y)
+// Returns an error if (x,y) lies outside this grid
+func (g *HashGrid[E]) Insert(x, y float64, e E) error {
+	if !g.view.contains(x, y) {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, x, y, &g.view)
+	}
+	g.insert(x, y, []E{e})
+	return nil
+}
+
+// Adds elems to the cell containing (x,y), which must lie within this grid's view
+func (g *HashGrid[E]) insert(x, y float64, elems []E) {
+	i := g.cell(x, y)
+	g.sizes[i] += len(elems)
+	g.size += len(elems)
+	ps := g.cells[i]
+	for j := range ps {
+		if ps[j].sameLoc(x, y) {
+			ps[j].elems = append(ps[j].elems, elems...)
+			return
+		}
+	}
+	g.cells[i] = append(ps, vpoint[E]{x: x, y: y, elems: elems})
+}
+
+// Applies fun to every element occurring within any view in vs in this grid
+func (g *HashGrid[E]) Survey(vs []*View, fun func(x, y float64, e E)) {
+	g.SurveyRegion(views(vs), fun)
+}
+
+// Applies fun to every element occurring within reg in this grid
+func (g *HashGrid[E]) SurveyRegion(reg Region, fun func(x, y float64, e E)) {
+	g.SurveyUntil(reg, func(x, y float64, e E) bool {
+		fun(x, y, e)
+		return true
+	})
+}
+
+// Applies fun to every element occurring within reg in this grid, stopping
+// as soon as fun returns false
+func (g *HashGrid[E]) SurveyUntil(reg Region, fun func(x, y float64, e E) bool) {
+	g.visitAll(reg, func(i int, _ *View) bool {
+		ps := g.cells[i]
+		for j := range ps {
+			p := &ps[j]
+			if reg.contains(p.x, p.y) {
+				for _, e := range p.elems {
+					if !fun(p.x, p.y, e) {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// Returns an iterator over the location of, and each element occurring within, reg in this grid
+// The grid must not be modified while iterating.
+func (g *HashGrid[E]) Within(reg Region) iter.Seq2[Point, E] {
+	return func(yield func(Point, E) bool) {
+		g.SurveyUntil(reg, func(x, y float64, e E) bool {
+			return yield(Point{x, y}, e)
+		})
+	}
+}
+
+// Returns the number of elements occurring within any view in vs in this grid
+func (g *HashGrid[E]) Count(vs []*View) int {
+	return g.CountRegion(views(vs))
+}
+
+// Returns the number of elements occurring within reg in this grid
+// Cells lying inside reg are counted without visiting their elements.
+func (g *HashGrid[E]) CountRegion(reg Region) int {
+	count := 0
+	g.visitAll(reg, func(i int, v *View) bool {
+		if reg.covers(v) {
+			count += g.sizes[i]
+			return true
+		}
+		for _, p := range g.cells[i] {
+			if reg.contains(p.x, p.y) {
+				count += len(p.elems)
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// Returns the number of elements lying within each cell of a grid of xCells by yCells laid over v
+// The cells of the heatmap need not line up with the cells of this grid.
+// Providing fewer than one cell along either axis will cause a panic
+func (g *HashGrid[E]) Heatmap(v *View, xCells, yCells int) [][]int {
+	h := newHeatmap(v, xCells, yCells)
+	g.SurveyRegion(v, func(x, y float64, _ E) {
+		h.add(x, y, 1)
+	})
+	return h.counts
+}
+
+// Returns clusters of the elements lying within v, each covering an area no wider or taller than resolution
+// Each cluster holds its centroid, the number of elements in it and up to reps of those elements.
+// Clusters are the cells of a grid, resolution wide and high, laid over v.
+// Providing a resolution which isn't positive, or a negative reps, will cause a panic
+func (g *HashGrid[E]) Clusters(v *View, resolution float64, reps int) []Cluster[E] {
+	c := newClusterer[E](v, resolution, reps)
+	c.addSurvey(g.SurveyRegion)
+	return c.clusters
+}
+
+// Dels each element, e, in this grid which lies within reg and for which pred(e) returns true
+func (g *HashGrid[E]) Del(reg Region, pred func(x, y float64, e E) bool) {
+	g.visitAll(reg, func(i int, _ *View) bool {
+		ps := g.cells[i]
+		for j := len(ps) - 1; j >= 0; j-- {
+			p := &ps[j]
+			if !reg.contains(p.x, p.y) {
+				continue
+			}
+			before := len(p.elems)
+			del(p, pred)
+			g.sizes[i] -= before - len(p.elems)
+			g.size -= before - len(p.elems)
+			if len(p.elems) == 0 {
+				last := len(ps) - 1
+				ps[j] = ps[last]
+				ps[last] = vpoint[E]{}
+				ps = ps[:last]
+			}
+		}
+		g.cells[i] = ps
+		return true
+	})
+}
+
+// Moves each element, e, lying at (oldX,oldY) for which pred(e) returns true to (newX,newY)
+// If (newX,newY) lies outside this grid nothing is moved and an error is returned.
+func (g *HashGrid[E]) Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e E) bool) error {
+	if !g.view.contains(newX, newY) {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, newX, newY, &g.view)
+	}
+	moved := make([]E, 0, 1)
+	g.Del(PointViewP(oldX, oldY), func(x, y float64, e E) bool {
+		if pred(x, y, e) {
+			moved = append(moved, e)
+			return true
+		}
+		return false
+	})
+	if len(moved) > 0 {
+		g.insert(newX, newY, moved)
+	}
+	return nil
+}
+
+// Returns up to k elements ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil
+// Squares of cells around (x,y), growing ever larger, are searched until the k
+// nearest elements are known, see nearestBySquares.
+func (g *HashGrid[E]) Nearest(x, y float64, k int, filter func(x, y float64, e E) bool) []E {
+	return nearestBySquares(&g.view, x, y, 2*math.Max(g.cellW, g.cellH), k, filter, g.SurveyRegion)
+}
+
+// Returns a snapshot of this grid as it is now
+// A grid can't share its cells with a snapshot, so every element is copied.
+func (g *HashGrid[E]) Snapshot() *Snapshot[E] {
+	return snapshotOf(&g.view, g.size, g.SurveyRegion)
+}
+
+// Returns a human friendly string representation of the occupied cells of this grid
+func (g *HashGrid[E]) String() string {
+	str := ""
+	for i, ps := range g.cells {
+		if len(ps) == 0 {
+			continue
+		}
+		str += g.blockView(i/g.rows, i/g.rows+1, i%g.rows, i%g.rows+1).String()
+		for j := range ps {
+			str += ps[j].String()
+		}
+		str += "\n"
+	}
+	return str
+}
@@ -0,0 +1,98 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// A heatmap counts the elements lying within each cell of a grid laid over a View.
+// The grid has xCells columns across the x axis and yCells rows along the y axis.
+// Each cell includes its left and top borders, only the cells along the right and
+// bottom of the grid include their right and bottom borders too. So every point in
+// the View lies in exactly one cell.
+type heatmap struct {
+	view           View
+	xCells, yCells int
+	cellW, cellH   float64
+	counts         [][]int
+}
+
+// Returns an empty heatmap over v
+// Providing fewer than one cell along either axis will cause a panic
+func newHeatmap(v *View, xCells, yCells int) *heatmap {
+	if xCells < 1 || yCells < 1 {
+		msg := fmt.Sprintf("Cannot create heatmap with fewer than one cell. xCells : %d yCells : %d", xCells, yCells)
+		panic(msg)
+	}
+	counts := make([][]int, xCells)
+	for i := range counts {
+		counts[i] = make([]int, yCells)
+	}
+	return &heatmap{view: *v, xCells: xCells, yCells: yCells, cellW: v.width() / float64(xCells), cellH: v.height() / float64(yCells), counts: counts}
+}
+
+// Returns the column and row of the cell containing (x,y), which must lie within this heatmap's view
+// Where this heatmap's view is infinite the position within a cell may not be a number,
+// so the column and row are always kept within the grid.
+func (h *heatmap) cell(x, y float64) (xi, yi int) {
+	xi, yi = h.xCells-1, h.yCells-1
+	if h.cellW > 0 {
+		xi = max(min(int(math.Floor((x-h.view.lx)/h.cellW)), xi), 0)
+	}
+	if h.cellH > 0 {
+		yi = max(min(int(math.Floor((y-h.view.ty)/h.cellH)), yi), 0)
+	}
+	return
+}
+
+// Adds n elements at (x,y) to their cell, if (x,y) lies within h's view
+func (h *heatmap) add(x, y float64, n int) {
+	if h.view.contains(x, y) {
+		xi, yi := h.cell(x, y)
+		h.counts[xi][yi] += n
+	}
+}
+
+// Adds the elements of st lying within h's view to their cells
+// A node whose view lies entirely within a single cell adds its count to that cell
+// without descending any further.
+func heatmapAdd[E any](h *heatmap, st subtree[E]) {
+	switch st.(type) {
+	case *leaf[E]:
+		l := st.(*leaf[E])
+		for i := range l.ps {
+			p := &l.ps[i]
+			if p.zeroed() {
+				break
+			}
+			h.add(p.x, p.y, len(p.elems))
+		}
+	case *node[E]:
+		n := st.(*node[E])
+		if h.view.covers(&n.view) {
+			lxi, tyi := h.cell(n.view.lx, n.view.ty)
+			rxi, byi := h.cell(n.view.rx, n.view.by)
+			if lxi == rxi && tyi == byi {
+				h.counts[lxi][tyi] += n.agg.Count
+				return
+			}
+		}
+		for i := range n.children {
+			if h.view.overlaps(n.children[i].View()) {
+				heatmapAdd(h, n.children[i])
+			}
+		}
+	}
+}
+
+// Returns the number of elements lying within each cell of a grid of xCells by yCells laid over v
+// The count for the cell in the i-th column across the x axis and the j-th row along
+// the y axis is at [i][j]. Each cell includes its left and top borders, the cells along
+// the right and bottom of v include their right and bottom borders too.
+// Any node lying entirely within a single cell is counted without visiting its elements.
+// Providing fewer than one cell along either axis will cause a panic
+func (r *QuadTree[E]) Heatmap(v *View, xCells, yCells int) [][]int {
+	h := newHeatmap(v, xCells, yCells)
+	heatmapAdd(h, r.rootNode)
+	return h.counts
+}
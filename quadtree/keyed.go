@@ -0,0 +1,229 @@
+package quadtree
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+var UnknownKeyErr = errors.New("No element with this key is stored in the index")
+
+// A Keyed index wraps another index, giving each element a unique key of type K.
+// The location of every element is kept in a map from its key, so an element can be
+// found, removed or moved knowing only its key, rather than where it was stored.
+// Every change to the wrapped index must be made through the Keyed index, or the map
+// and the index will drift apart.
+// Inserting an element whose key is already stored replaces the stored element.
+// When E is interface{} a Keyed index implements the public interface T.
+// A Keyed index does not support concurrent access.
+type Keyed[K comparable, E any] struct {
+	index Index[E]
+	key   func(e E) K
+	locs  map[K]Point
+}
+
+// Returns a new Keyed index wrapping index, which should be empty, keying each element by key(e)
+func NewKeyed[K comparable, E any](index Index[E], key func(e E) K) *Keyed[K, E] {
+	return &Keyed[K, E]{index: index, key: key, locs: make(map[K]Point)}
+}
+
+// Returns a predicate matching the elements whose key is k
+func (kd *Keyed[K, E]) keyIs(k K) func(x, y float64, e E) bool {
+	return func(_, _ float64, e E) bool {
+		return kd.key(e) == k
+	}
+}
+
+// Deletes the element keyed k, which is stored at p, returning it
+func (kd *Keyed[K, E]) take(k K, p Point) []E {
+	taken := make([]E, 0, 1)
+	kd.index.Del(PointViewP(p.X, p.Y), func(_, _ float64, e E) bool {
+		if kd.key(e) == k {
+			taken = append(taken, e)
+			return true
+		}
+		return false
+	})
+	delete(kd.locs, k)
+	return taken
+}
+
+// Returns the View for this index
+func (kd *Keyed[K, E]) View() *View {
+	return kd.index.View()
+}
+
+// Returns the number of elements in this index
+func (kd *Keyed[K, E]) Len() int {
+	return len(kd.locs)
+}
+
+// Inserts e into this index at (x,y)
+// If an element with the same key is already stored it is removed, wherever it lies.
+// Returns an error, leaving this index unchanged, if (x,y) lies outside this index
+// and it cannot grow to contain it
+func (kd *Keyed[K, E]) Insert(x, y float64, e E) error {
+	k := kd.key(e)
+	p, ok := kd.locs[k]
+	var replaced []E
+	if ok {
+		replaced = kd.take(k, p)
+	}
+	if err := kd.index.Insert(x, y, e); err != nil {
+		// p lay within the index, so putting back the replaced element can't fail
+		for _, old := range replaced {
+			kd.index.Insert(p.X, p.Y, old)
+		}
+		if ok {
+			kd.locs[k] = p
+		}
+		return err
+	}
+	kd.locs[k] = Point{x, y}
+	return nil
+}
+
+// Returns the location of, and the element keyed k
+// Returns false if no element keyed k is stored in this index
+func (kd *Keyed[K, E]) Get(k K) (Point, E, bool) {
+	p, ok := kd.locs[k]
+	var found E
+	if ok {
+		ok = false
+		kd.index.SurveyUntil(PointViewP(p.X, p.Y), func(_, _ float64, e E) bool {
+			if kd.key(e) == k {
+				found, ok = e, true
+			}
+			return !ok
+		})
+	}
+	return p, found, ok
+}
+
+// Removes the element keyed k from this index
+// Returns false if no element keyed k is stored in this index
+func (kd *Keyed[K, E]) Remove(k K) bool {
+	p, ok := kd.locs[k]
+	if ok {
+		kd.take(k, p)
+	}
+	return ok
+}
+
+// Moves the element keyed k to (x,y)
+// Returns an error, having moved nothing, if no element keyed k is stored in this index,
+// or if (x,y) lies outside this index and it cannot grow to contain it.
+// If several elements keyed k were stored, because the wrapped index was changed directly,
+// they are all moved to (x,y), which becomes the location of k, and an error is returned.
+func (kd *Keyed[K, E]) MoveTo(k K, x, y float64) error {
+	p, ok := kd.locs[k]
+	if !ok {
+		return fmt.Errorf("%w: %v", UnknownKeyErr, k)
+	}
+	moved := 0
+	isK := kd.keyIs(k)
+	count := func(px, py float64, e E) bool {
+		if isK(px, py, e) {
+			moved++
+			return true
+		}
+		return false
+	}
+	if err := kd.index.Move(p.X, p.Y, x, y, count); err != nil {
+		return err
+	}
+	switch moved {
+	case 0:
+		return fmt.Errorf("%w: %v not found at (%.3f,%.3f)", UnknownKeyErr, k, p.X, p.Y)
+	case 1:
+		kd.locs[k] = Point{x, y}
+		return nil
+	default:
+		kd.locs[k] = Point{x, y}
+		return fmt.Errorf("%d elements keyed %v moved from (%.3f,%.3f) to (%.3f,%.3f)", moved, k, p.X, p.Y, x, y)
+	}
+}
+
+// Applies fun to every element occurring within any view in vs in this index
+func (kd *Keyed[K, E]) Survey(vs []*View, fun func(x, y float64, e E)) {
+	kd.index.Survey(vs, fun)
+}
+
+// Applies fun to every element occurring within reg in this index
+func (kd *Keyed[K, E]) SurveyRegion(reg Region, fun func(x, y float64, e E)) {
+	kd.index.SurveyRegion(reg, fun)
+}
+
+// Applies fun to every element occurring within reg in this index, stopping
+// as soon as fun returns false
+func (kd *Keyed[K, E]) SurveyUntil(reg Region, fun func(x, y float64, e E) bool) {
+	kd.index.SurveyUntil(reg, fun)
+}
+
+// Returns an iterator over the location of, and each element occurring within, reg in this index
+// The index must not be modified while iterating.
+func (kd *Keyed[K, E]) Within(reg Region) iter.Seq2[Point, E] {
+	return kd.index.Within(reg)
+}
+
+// Returns the number of elements occurring within any view in vs in this index
+func (kd *Keyed[K, E]) Count(vs []*View) int {
+	return kd.index.Count(vs)
+}
+
+// Returns the number of elements occurring within reg in this index
+func (kd *Keyed[K, E]) CountRegion(reg Region) int {
+	return kd.index.CountRegion(reg)
+}
+
+// Returns the number of elements lying within each cell of a grid of xCells by yCells laid over v
+func (kd *Keyed[K, E]) Heatmap(v *View, xCells, yCells int) [][]int {
+	return kd.index.Heatmap(v, xCells, yCells)
+}
+
+// Returns clusters of the elements lying within v, each covering an area no wider or taller than resolution
+func (kd *Keyed[K, E]) Clusters(v *View, resolution float64, reps int) []Cluster[E] {
+	return kd.index.Clusters(v, resolution, reps)
+}
+
+// Dels each element, e, in this index which lies within reg and for which pred(e) returns true
+// The key of each deleted element is forgotten.
+func (kd *Keyed[K, E]) Del(reg Region, pred func(x, y float64, e E) bool) {
+	kd.index.Del(reg, func(x, y float64, e E) bool {
+		if pred(x, y, e) {
+			delete(kd.locs, kd.key(e))
+			return true
+		}
+		return false
+	})
+}
+
+// Moves each element, e, lying at (oldX,oldY) for which pred(e) returns true to (newX,newY)
+// The location of each moved element is updated with it.
+// Returns an error, having moved nothing, if (newX,newY) lies outside this index
+// and it cannot grow to contain it
+func (kd *Keyed[K, E]) Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e E) bool) error {
+	return kd.index.Move(oldX, oldY, newX, newY, func(x, y float64, e E) bool {
+		if pred(x, y, e) {
+			kd.locs[kd.key(e)] = Point{newX, newY}
+			return true
+		}
+		return false
+	})
+}
+
+// Returns up to k elements ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil
+func (kd *Keyed[K, E]) Nearest(x, y float64, k int, filter func(x, y float64, e E) bool) []E {
+	return kd.index.Nearest(x, y, k, filter)
+}
+
+// Returns a snapshot of the wrapped index as it is now
+func (kd *Keyed[K, E]) Snapshot() *Snapshot[E] {
+	return kd.index.Snapshot()
+}
+
+// Returns a human friendly string representation of the wrapped index
+func (kd *Keyed[K, E]) String() string {
+	return kd.index.String()
+}
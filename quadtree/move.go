@@ -0,0 +1,59 @@
+package quadtree
+
+// Moves each element, e, lying at (oldX,oldY) for which pred(e) returns true to (newX,newY)
+// The move is made within the smallest subtree whose view contains both points.
+// When both points lie within the same leaf the elements are relocated in place,
+// and the tree is only restructured if the leaf overflows.
+// The nodes above that subtree are then resummarised, as their coordinate sums change.
+// If (newX,newY) lies outside this tree's View, and the tree's BoundsPolicy won't
+// let it grow, nothing is moved and an error is returned.
+func (r *QuadTree[E]) Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e E) bool) error {
+	if err := r.accommodate(newX, newY); err != nil {
+		return err
+	}
+	st := &r.rootNode
+	var path []*node[E]
+	for {
+		n, ok := r.own(st).(*node[E])
+		if !ok {
+			break
+		}
+		// A point on a border is stored in the first child containing it, see node.insert
+		next := st
+		for i := range n.children {
+			v := n.children[i].View()
+			if v.contains(oldX, oldY) {
+				if v.contains(newX, newY) {
+					next = &n.children[i]
+				}
+				break
+			}
+		}
+		if next == st {
+			break
+		}
+		path = append(path, n)
+		st = next
+	}
+	// The root node must never be replaced, so it is not given a pointer to itself
+	var inPtr *subtree[E]
+	if st != &r.rootNode {
+		inPtr = st
+	}
+	moved := make([]E, 0, 1)
+	collect := func(x, y float64, e E) bool {
+		if pred(x, y, e) {
+			moved = append(moved, e)
+			return true
+		}
+		return false
+	}
+	(*st).del(PointViewP(oldX, oldY), collect, inPtr, r)
+	if len(moved) > 0 {
+		(*st).insert(newX, newY, moved, st, r)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].resummarise(r.key)
+	}
+	return nil
+}
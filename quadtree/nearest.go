@@ -0,0 +1,146 @@
+package quadtree
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// A candidate is an entry in the priority queue used by a nearest neighbour search.
+// It is either a subtree waiting to be expanded, or a single element waiting to be
+// collected. distSq is the squared distance from the search point to the element,
+// or the smallest possible squared distance to anything inside the subtree.
+type candidate[E any] struct {
+	distSq float64
+	st     subtree[E]
+	e      E
+}
+
+// A min-heap of candidates ordered by distSq, implements heap.Interface
+type candidateHeap[E any] []candidate[E]
+
+func (h candidateHeap[E]) Len() int {
+	return len(h)
+}
+
+func (h candidateHeap[E]) Less(i, j int) bool {
+	return h[i].distSq < h[j].distSq
+}
+
+func (h candidateHeap[E]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *candidateHeap[E]) Push(c interface{}) {
+	*h = append(*h, c.(candidate[E]))
+}
+
+func (h *candidateHeap[E]) Pop() interface{} {
+	old := *h
+	last := len(old) - 1
+	c := old[last]
+	old[last] = candidate[E]{}
+	*h = old[:last]
+	return c
+}
+
+// Returns up to k element candidates from the subtrees sts ordered by their distance from (x,y), nearest first.
+// Only elements for which filter returns true are collected, a nil filter accepts every element.
+// This is a best-first search. Subtrees are expanded in order of the smallest
+// possible distance between (x,y) and their view, so once k elements have been
+// popped from the queue no unexpanded subtree can contain anything nearer.
+func nearestIn[E any](sts []subtree[E], x, y float64, k int, filter func(x, y float64, e E) bool) []candidate[E] {
+	if k <= 0 {
+		return []candidate[E]{}
+	}
+	results := make([]candidate[E], 0, k)
+	h := &candidateHeap[E]{}
+	for _, st := range sts {
+		heap.Push(h, candidate[E]{distSq: st.View().distSq(x, y), st: st})
+	}
+	for h.Len() > 0 && len(results) < k {
+		c := heap.Pop(h).(candidate[E])
+		switch c.st.(type) {
+		case nil:
+			results = append(results, c)
+		case *leaf[E]:
+			l := c.st.(*leaf[E])
+			for i := range l.ps {
+				p := &l.ps[i]
+				if p.zeroed() {
+					break
+				}
+				d := distSq(x, y, p.x, p.y)
+				for _, e := range p.elems {
+					if filter == nil || filter(p.x, p.y, e) {
+						heap.Push(h, candidate[E]{distSq: d, e: e})
+					}
+				}
+			}
+		case *node[E]:
+			n := c.st.(*node[E])
+			for i := range n.children {
+				child := n.children[i]
+				if !child.isEmptyLeaf() {
+					heap.Push(h, candidate[E]{distSq: child.View().distSq(x, y), st: child})
+				}
+			}
+		}
+	}
+	return results
+}
+
+// Returns up to k elements within v ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil.
+// Used by indexes with no subtrees to search best-first. Squares centred on (x,y) are
+// surveyed, starting side wide and doubling, until one holds k elements no further
+// from (x,y) than half its side, or covers v. Nothing outside such a square can be nearer.
+// Squares around a point which isn't finite never cover v, so nothing is returned for one.
+func nearestBySquares[E any](v *View, x, y, side float64, k int, filter func(x, y float64, e E) bool, survey func(reg Region, fun func(x, y float64, e E))) []E {
+	if k <= 0 || math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return []E{}
+	}
+	if !(side > 0) {
+		side = math.Max(v.width(), v.height())
+	}
+	// A view with no area gives no sense of scale, any positive side will do
+	if !(side > 0) {
+		side = 1
+	}
+	for {
+		half := side / 2
+		sq := NewViewP(x-half, x+half, y-half, y+half)
+		found := make([]candidate[E], 0, k)
+		survey(sq, func(ex, ey float64, e E) {
+			if filter == nil || filter(ex, ey, e) {
+				found = append(found, candidate[E]{distSq: distSq(x, y, ex, ey), e: e})
+			}
+		})
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].distSq < found[j].distSq
+		})
+		if len(found) > k {
+			found = found[:k]
+		}
+		if (len(found) == k && found[k-1].distSq <= half*half) || sq.covers(v) || math.IsInf(side, 1) {
+			return candidateElems(found)
+		}
+		side *= 2
+	}
+}
+
+// Returns the elements held by a slice of element candidates
+func candidateElems[E any](cs []candidate[E]) []E {
+	elems := make([]E, len(cs))
+	for i := range cs {
+		elems[i] = cs[i].e
+	}
+	return elems
+}
+
+// Returns the squared distance between the points (x1,y1) and (x2,y2)
+func distSq(x1, y1, x2, y2 float64) float64 {
+	dx := x1 - x2
+	dy := y1 - y2
+	return dx*dx + dy*dy
+}
@@ -0,0 +1,125 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// A Polygon is the region enclosed by a simple polygon, less the regions
+// enclosed by any of its holes. Each ring is a sequence of vertices, the last
+// vertex is implicitly joined to the first.
+// Points lying exactly on an edge may be classified either way.
+type Polygon struct {
+	outer  []Point
+	holes  [][]Point
+	bounds View
+}
+
+// Returns a new Polygon with the outer boundary and holes provided
+// Providing any ring with fewer than three vertices will cause a panic
+func NewPolygon(outer []Point, holes ...[]Point) *Polygon {
+	checkRing(outer)
+	for _, h := range holes {
+		checkRing(h)
+	}
+	lx, rx := math.Inf(1), math.Inf(-1)
+	ty, by := math.Inf(1), math.Inf(-1)
+	for _, p := range outer {
+		lx = math.Min(lx, p.X)
+		rx = math.Max(rx, p.X)
+		ty = math.Min(ty, p.Y)
+		by = math.Max(by, p.Y)
+	}
+	return &Polygon{outer: outer, holes: holes, bounds: NewView(lx, rx, ty, by)}
+}
+
+// Panics if ring cannot enclose an area
+func checkRing(ring []Point) {
+	if len(ring) < 3 {
+		msg := fmt.Sprintf("Cannot create polygon ring with fewer than three vertices. vertices : %d", len(ring))
+		panic(msg)
+	}
+}
+
+// Indicates whether this Polygon contains the point (x,y)
+func (pg *Polygon) contains(x, y float64) bool {
+	if !pg.bounds.contains(x, y) || !ringContains(pg.outer, x, y) {
+		return false
+	}
+	for _, h := range pg.holes {
+		if ringContains(h, x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// Indicates whether any part of this Polygon lies within v
+// A view lying entirely inside a hole does not overlap the polygon.
+func (pg *Polygon) overlaps(v *View) bool {
+	if !pg.bounds.overlaps(v) || !ringOverlaps(pg.outer, v) {
+		return false
+	}
+	for _, h := range pg.holes {
+		if ringCovers(h, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Indicates whether all of v lies within this Polygon
+// v must lie inside the outer ring and touch none of the holes.
+func (pg *Polygon) covers(v *View) bool {
+	if !pg.bounds.covers(v) || !ringCovers(pg.outer, v) {
+		return false
+	}
+	for _, h := range pg.holes {
+		if ringOverlaps(h, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Indicates whether the point (x,y) lies inside ring
+// Counts the edges crossed by a ray cast from (x,y) in the positive x direction,
+// an odd number of crossings means (x,y) is inside.
+func ringContains(ring []Point, x, y float64) bool {
+	in := false
+	j := len(ring) - 1
+	for i := range ring {
+		pi, pj := ring[i], ring[j]
+		if (pi.Y > y) != (pj.Y > y) {
+			crossX := pi.X + (y-pi.Y)*(pj.X-pi.X)/(pj.Y-pi.Y)
+			if x < crossX {
+				in = !in
+			}
+		}
+		j = i
+	}
+	return in
+}
+
+// Indicates whether any edge of ring passes through v
+func ringCrosses(ring []Point, v *View) bool {
+	j := len(ring) - 1
+	for i := range ring {
+		if v.crossedBy(ring[j].X, ring[j].Y, ring[i].X, ring[i].Y) {
+			return true
+		}
+		j = i
+	}
+	return false
+}
+
+// Indicates whether the area enclosed by ring and v intersect
+// Either an edge of ring passes through v, or v lies entirely inside ring.
+func ringOverlaps(ring []Point, v *View) bool {
+	return ringCrosses(ring, v) || ringContains(ring, v.lx, v.ty)
+}
+
+// Indicates whether v lies entirely inside ring
+func ringCovers(ring []Point, v *View) bool {
+	return !ringCrosses(ring, v) && ringContains(ring, v.lx, v.ty)
+}
@@ -2,17 +2,34 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"github.com/fmstephe/location_server/quadtree"
 	"math/rand"
 	"os"
 	"runtime/pprof"
+	"time"
 )
 
 const iterations = 1
 const elemCount = 1000000
 const treeSize = 100
 
+// The number of cells along each side of a hash grid, about sixteen elements to a cell
+const gridCells = 250
+
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
+var bulk = flag.Bool("bulk", false, "bulk load the quadtree instead of inserting each element")
+var index = flag.String("index", "all", "the index to profile: quadtree, hashgrid, zorder or all")
+
+// The indexes which can be profiled, each with a constructor for an empty index
+var indexes = []struct {
+	name     string
+	newIndex func() quadtree.T
+}{
+	{"quadtree", func() quadtree.T { return quadtree.NewQuadTree(0, treeSize, 0, treeSize, elemCount/6) }},
+	{"hashgrid", func() quadtree.T { return quadtree.NewHashGrid[interface{}](0, treeSize, 0, treeSize, gridCells, gridCells) }},
+	{"zorder", func() quadtree.T { return quadtree.NewZOrderIndex[interface{}](0, treeSize, 0, treeSize) }},
+}
 
 func main() {
 	flag.Parse()
@@ -21,20 +38,49 @@ func main() {
 		pprof.StartCPUProfile(f)
 		defer pprof.StopCPUProfile()
 	}
-	for i := 0; i < iterations; i++ {
-		tree := quadtree.NewQuadTree(0, treeSize, 0, treeSize, elemCount/6)
+	found := false
+	for _, idx := range indexes {
+		if *index == "all" || *index == idx.name {
+			found = true
+			for i := 0; i < iterations; i++ {
+				profile(idx.name, idx.newIndex)
+			}
+		}
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown index %q\n", *index)
+		os.Exit(1)
+	}
+}
+
+// Fills a new index with elemCount random elements, surveys all of them and then deletes them all
+// The time taken by each step is printed.
+func profile(name string, newIndex func() quadtree.T) {
+	start := time.Now()
+	var tree quadtree.T
+	if *bulk && name == "quadtree" {
+		entries := make([]quadtree.Entry[interface{}], elemCount)
+		for i := range entries {
+			entries[i] = quadtree.Entry[interface{}]{X: rand.Float64() * treeSize, Y: rand.Float64() * treeSize, Elem: i}
+		}
+		tree = quadtree.NewBulk(0, treeSize, 0, treeSize, elemCount/6, entries)
+	} else {
+		tree = newIndex()
 		for i := 0; i < elemCount; i++ {
 			x := rand.Float64() * treeSize
 			y := rand.Float64() * treeSize
 			tree.Insert(x, y, i)
 		}
-		vs := []*quadtree.View{tree.View()}
-		col := make([]interface{}, 0, elemCount)
-		fun := func(x, y float64, e interface{}) {
-			col = append(col, e)
-		}
-		tree.Survey(vs, fun)
-		tree.Del(tree.View(), quadtree.SimpleDelete())
-		println(col)
 	}
+	inserted := time.Now()
+	vs := []*quadtree.View{tree.View()}
+	col := make([]interface{}, 0, elemCount)
+	fun := func(x, y float64, e interface{}) {
+		col = append(col, e)
+	}
+	tree.Survey(vs, fun)
+	surveyed := time.Now()
+	tree.Del(tree.View(), quadtree.SimpleDelete())
+	deleted := time.Now()
+	fmt.Printf("%s: insert %v survey %v (%d elements) delete %v\n", name, inserted.Sub(start), surveyed.Sub(inserted), len(col), deleted.Sub(surveyed))
 }
@@ -2,16 +2,21 @@ package quadtree
 
 import (
 	"fmt"
+	"iter"
 )
 
 // Private interface for quadtree nodes. Implemented by both node and leaf.
-type subtree interface {
+type subtree[E any] interface {
 	//
-	insert(x, y float64, elems []interface{}, p *subtree, r *root)
+	insert(x, y float64, elems []E, p *subtree[E], r *QuadTree[E])
 	//
-	survey(view []*View, fun func(x, y float64, e interface{}))
+	survey(reg Region, fun func(x, y float64, e E))
 	//
-	del(view *View, pred func(x, y float64, e interface{}) bool, p *subtree, r *root)
+	surveyUntil(reg Region, fun func(x, y float64, e E) bool) bool
+	//
+	del(reg Region, pred func(x, y float64, e E) bool, p *subtree[E], r *QuadTree[E])
+	//
+	aggregate(reg Region, key func(e E) float64) Aggregate
 	//
 	isEmptyLeaf() bool
 	//
@@ -27,33 +32,43 @@ type subtree interface {
 // topY down to bottomY along the y axis
 // leftX < rightX
 // topY < bottomY
+// The tree stores elements of any type, as interface{}, see New for a type-safe quadtree.
 func NewQuadTree(leftX, rightX, topY, bottomY float64, leafAllocation int64) T {
+	return New[interface{}](leftX, rightX, topY, bottomY, leafAllocation)
+}
+
+// Returns a new empty QuadTree, storing elements of type E, whose View extends from
+// leftX to rightX across the x axis and
+// topY down to bottomY along the y axis
+// leftX < rightX
+// topY < bottomY
+func New[E any](leftX, rightX, topY, bottomY float64, leafAllocation int64) *QuadTree[E] {
 	var newView = NewViewP(leftX, rightX, topY, bottomY)
-	return newRoot(newView, leafAllocation)
+	return newRoot[E](newView, leafAllocation)
 }
 
 // A point with a slice of stored elements
-type vpoint struct {
+type vpoint[E any] struct {
 	x, y  float64
-	elems []interface{}
+	elems []E
 }
 
 // Indicates whether a vpoint is zeroed, i.e. uninitialised
-func (np *vpoint) zeroed() bool {
+func (np *vpoint[E]) zeroed() bool {
 	return np.elems == nil
 }
 
 // Resets this vpoint back to its uninitialised state
-func (np *vpoint) zeroOut() {
+func (np *vpoint[E]) zeroOut() {
 	np.elems = nil
 }
 
 // Indicates whether a vpoint has the same x,y coords as those passed in
-func (np *vpoint) sameLoc(x, y float64) bool {
+func (np *vpoint[E]) sameLoc(x, y float64) bool {
 	return np.x == x && np.y == y
 }
 
-func (np *vpoint) String() string {
+func (np *vpoint[E]) String() string {
 	return fmt.Sprintf("(%v,%.3f,%.3f)", np.elems, np.x, np.y)
 }
 
@@ -67,14 +82,19 @@ const LEAF_SIZE = 16
 // of lesser index are also non-empty i.e. if ps[3] is non-empty then so
 // are ps[2], ps[1] and ps[0], while ps[4] or greater have no such constraints.
 // The vpoints are not ordered in any way with respect to their geometric locations.
+// A leaf which is already as deep, or as small, as the tree allows is never divided,
+// see SetDepthLimits. Instead it overflows, growing ps beyond LEAF_SIZE vpoints to
+// hold every point inserted into it. An overflowing leaf never shrinks below LEAF_SIZE
+// vpoints.
 // A leaf is disposable if it was allocated outside the static leaf array, see root 
 // below. If a leaf is marked as disposable it will not be recycled, but abandoned to
 // the whimsy of the garbage collector.
-type leaf struct {
-	nextFree   *leaf
+type leaf[E any] struct {
+	nextFree   *leaf[E]
 	view       View
-	ps         [LEAF_SIZE]vpoint
+	ps         []vpoint[E]
 	disposable bool
+	gen        uint64
 }
 
 // Inserts each of the elements in elems into this leaf. There are three
@@ -89,7 +109,7 @@ type leaf struct {
 //					- Replace this leaf with an intermediate node and re-allocate 
 //					all of the elements in this leaf as well as those in elems into
 //					the new node
-func (l *leaf) insert(x, y float64, elems []interface{}, inPtr *subtree, r *root) {
+func (l *leaf[E]) insert(x, y float64, elems []E, inPtr *subtree[E], r *QuadTree[E]) {
 	for i := range l.ps {
 		if l.ps[i].zeroed() {
 			l.ps[i].x = x
@@ -102,30 +122,36 @@ func (l *leaf) insert(x, y float64, elems []interface{}, inPtr *subtree, r *root
 			return
 		}
 	}
-	// This leaf is full we need to create an intermediary node to divide it up
+	// This leaf is full, it overflows if it can't be divided any further
+	if !r.canDivide(&l.view) {
+		l.ps = append(l.ps, vpoint[E]{x: x, y: y, elems: elems})
+		return
+	}
+	// Otherwise we need to create an intermediary node to divide it up
 	newIntNode(x, y, elems, inPtr, l, r)
 }
 
 // This function creates a new node and adds all of the elements contained in l to it, 
 // plus the new elements in elems. The pointer which previously pointed to l is 
-// pointed at the new node. l is recycled.
-func newIntNode(x, y float64, elems []interface{}, inPtr *subtree, l *leaf, r *root) {
-	var newNode subtree
-	newNode = r.newNode(l.View())
+// pointed at the new node, before the elements are added, so that the depth of any
+// of its leaves divided in turn can be found from the root. l is recycled.
+func newIntNode[E any](x, y float64, elems []E, inPtr *subtree[E], l *leaf[E], r *QuadTree[E]) {
+	var newNode subtree[E]
+	newNode = r.newSplitNode(l.View(), l.ps, vpoint[E]{x: x, y: y, elems: elems})
+	*inPtr = newNode // Redirect the old leaf's reference to this intermediate node
 	for _, p := range l.ps {
 		newNode.insert(p.x, p.y, p.elems, nil, r) // Does not require an inPtr param as we are passing into a *node
 	}
 	newNode.insert(x, y, elems, nil, r) // Does not require an inPtr param as we are passing into a *node
-	*inPtr = newNode                    // Redirect the old leaf's reference to this intermediate node
 	r.recycleLeaf(l)
 }
 
 // Applies fun to each of the elements contained in this leaf
-// which appear within view.
-func (l *leaf) survey(vs []*View, fun func(x, y float64, e interface{})) {
+// which appear within reg.
+func (l *leaf[E]) survey(reg Region, fun func(x, y float64, e E)) {
 	for i := range l.ps {
 		p := &l.ps[i]
-		if !p.zeroed() && contains(vs, p.x, p.y) {
+		if !p.zeroed() && reg.contains(p.x, p.y) {
 			for i := range p.elems {
 				fun(p.x, p.y, p.elems[i])
 			}
@@ -133,28 +159,45 @@ func (l *leaf) survey(vs []*View, fun func(x, y float64, e interface{})) {
 	}
 }
 
+// Applies fun to each of the elements contained in this leaf
+// which appear within reg, until fun returns false.
+// Returns false if fun returned false, true otherwise.
+func (l *leaf[E]) surveyUntil(reg Region, fun func(x, y float64, e E) bool) bool {
+	for i := range l.ps {
+		p := &l.ps[i]
+		if !p.zeroed() && reg.contains(p.x, p.y) {
+			for i := range p.elems {
+				if !fun(p.x, p.y, p.elems[i]) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
 // Dels each element, e, in this leaf which satisfies two conditions
-// 	1: e lies within view
+// 	1: e lies within reg
 //	2: pred(e) returns true
 // pred may have side-effects allowing for arbitrary processing of deld elements.
 // It is worth noting that if this leaf becomes empty it is the responsibility
 // of this leaf's parent node to recycle it (when it chooses).
-func (l *leaf) del(view *View, pred func(x, y float64, e interface{}) bool, _ *subtree, _ *root) {
+func (l *leaf[E]) del(reg Region, pred func(x, y float64, e E) bool, _ *subtree[E], _ *QuadTree[E]) {
 	for i := range l.ps {
 		point := &l.ps[i]
-		if !point.zeroed() && view.contains(point.x, point.y) {
+		if !point.zeroed() && reg.contains(point.x, point.y) {
 			del(point, pred)
 			if len(point.elems) == 0 {
 				point.zeroOut()
 			}
 		}
 	}
-	restoreOrder(&l.ps)
+	l.ps = restoreOrder(l.ps)
 	return
 }
 
 // Dels each element, e, from elems where pred(e) returns true.
-func del(p *vpoint, pred func(x, y float64, e interface{}) bool) {
+func del[E any](p *vpoint[E], pred func(x, y float64, e E) bool) {
 	for i := len(p.elems) - 1; i >= 0; i-- {
 		if pred(p.x, p.y, p.elems[i]) {
 			// Fast del from slice
@@ -168,7 +211,9 @@ func del(p *vpoint, pred func(x, y float64, e interface{}) bool) {
 
 // Restores the leaf invariant that "if any vpoint is non-empty, then all vpoints 
 // of lesser index are also non-empty" by rearranging the elements of ps.
-func restoreOrder(ps *[LEAF_SIZE]vpoint) {
+// The empty vpoints at the end of an overflowing leaf are dropped, so the slice
+// returned holds no fewer than LEAF_SIZE vpoints and no more than are needed.
+func restoreOrder[E any](ps []vpoint[E]) []vpoint[E] {
 	for i := range ps {
 		if ps[i].zeroed() {
 			for j := i + 1; j < len(ps); j++ {
@@ -180,25 +225,30 @@ func restoreOrder(ps *[LEAF_SIZE]vpoint) {
 			}
 		}
 	}
+	n := len(ps)
+	for n > LEAF_SIZE && ps[n-1].zeroed() {
+		n--
+	}
+	return ps[:n]
 }
 
 // Returns a pointer to the View of this leaf
-func (l *leaf) View() *View {
+func (l *leaf[E]) View() *View {
 	return &l.view
 }
 
 // Sets the view for this leaf
-func (l *leaf) setView(view *View) {
+func (l *leaf[E]) setView(view *View) {
 	l.view = *view
 }
 
 // Indicates whether or not this leaf contains any elements
-func (l *leaf) isEmptyLeaf() bool {
+func (l *leaf[E]) isEmptyLeaf() bool {
 	return l.ps[0].zeroed()
 }
 
 // Returns a human friendly string representation of this leaf
-func (l *leaf) String() string {
+func (l *leaf[E]) String() string {
 	var str = l.view.String()
 	for _, p := range l.ps {
 		str += p.String()
@@ -213,43 +263,70 @@ func (l *leaf) String() string {
 // Each subtree will have a view containing one of four quarters of
 // this node's view. Every subtree is guaranteed to be non-nil and
 // may be either a node or a leaf struct.
-type node struct {
-	nextFree   *node
+// A node also holds the aggregate, and the sums of the coordinates, of
+// every element beneath it.
+type node[E any] struct {
+	nextFree   *node[E]
 	view       View
-	children   [4]subtree
+	children   [4]subtree[E]
 	disposable bool
+	gen        uint64
+	agg        Aggregate
+	sumX, sumY float64
 }
 
 // Inserts elems into the single child subtree whose view contains (x,y)
-func (n *node) insert(x, y float64, elems []interface{}, _ *subtree, r *root) {
+// The children's views share their borders, a point lying on a border belongs to the
+// first child containing it. So each point is stored in exactly one leaf.
+// The elems are added to this node's aggregate and coordinate sums.
+func (n *node[E]) insert(x, y float64, elems []E, _ *subtree[E], r *QuadTree[E]) {
 	for i := range n.children {
 		if n.children[i].View().contains(x, y) {
-			n.children[i].insert(x, y, elems, &n.children[i], r)
+			r.own(&n.children[i]).insert(x, y, elems, &n.children[i], r)
+			aggregateElems(&n.agg, r.key, elems)
+			n.sumX += x * float64(len(elems))
+			n.sumY += y * float64(len(elems))
+			return
 		}
 	}
 }
 
-// Calls survey on each child subtree whose view overlaps with vs 
-func (n *node) survey(vs []*View, fun func(x, y float64, e interface{})) {
+// Calls survey on each child subtree whose view overlaps with reg
+func (n *node[E]) survey(reg Region, fun func(x, y float64, e E)) {
 	for i := range n.children {
 		child := n.children[i]
-		if overlaps(vs, child.View()) {
-			n.children[i].survey(vs, fun)
+		if reg.overlaps(child.View()) {
+			n.children[i].survey(reg, fun)
 		}
 	}
 }
 
-// Calls del on each child subtree whose view overlaps view
-func (n *node) del(view *View, pred func(x, y float64, e interface{}) bool, inPtr *subtree, r *root) {
+// Calls surveyUntil on each child subtree whose view overlaps with reg
+// Stops as soon as any child's survey is stopped.
+// Returns false if the survey was stopped, true otherwise.
+func (n *node[E]) surveyUntil(reg Region, fun func(x, y float64, e E) bool) bool {
+	for i := range n.children {
+		child := n.children[i]
+		if reg.overlaps(child.View()) && !child.surveyUntil(reg, fun) {
+			return false
+		}
+	}
+	return true
+}
+
+// Calls del on each child subtree whose view overlaps reg
+// This node's aggregate and coordinate sums are then recalculated from its children.
+func (n *node[E]) del(reg Region, pred func(x, y float64, e E) bool, inPtr *subtree[E], r *QuadTree[E]) {
 	allEmpty := true
 	for i := range n.children {
-		if n.children[i].View().overlaps(view) {
-			n.children[i].del(view, pred, &n.children[i], r)
+		if reg.overlaps(n.children[i].View()) {
+			r.own(&n.children[i]).del(reg, pred, &n.children[i], r)
 		}
 		allEmpty = allEmpty && n.children[i].isEmptyLeaf()
 	}
+	n.resummarise(r.key)
 	if allEmpty && inPtr != nil {
-		var l subtree
+		var l subtree[E]
 		l = r.newLeaf(n.View()) // TODO Think hard about whether this could error out
 		*inPtr = l
 		r.recycleNode(n)
@@ -258,35 +335,43 @@ func (n *node) del(view *View, pred func(x, y float64, e interface{}) bool, inPt
 }
 
 // Returns the View for this node
-func (n *node) View() *View {
+func (n *node[E]) View() *View {
 	return &n.view
 }
 
 // Sets the view for this node
-func (n *node) setView(view *View) {
+func (n *node[E]) setView(view *View) {
 	n.view = *view
 }
 
 // Always returns false - a node is never an empty leaf
-func (n *node) isEmptyLeaf() bool {
+func (n *node[E]) isEmptyLeaf() bool {
 	return false
 }
 
 // Returns a human friendly string representing this node, including its children.
-func (n *node) String() string {
+func (n *node[E]) String() string {
 	return "<" + n.view.String() + "-\n" + n.children[0].String() + ", \n" + n.children[1].String() + ", \n" + n.children[2].String() + ", \n" + n.children[3].String() + ">"
 }
 
+// A QuadTree is the root of a tree storing elements of type E.
 // Each tree has a single root.
 // The root is responsible for:
-//	- Implementing the quadtree public interface T.
+//	- Implementing the quadtree public interface T, when E is interface{}.
 //	- Allocating and recycling leaf and node elements
-type root struct {
-	freeNode *node
-	freeLeaf *leaf
-	leaves   []leaf
-	nodes    []node
-	rootNode subtree
+type QuadTree[E any] struct {
+	freeNode *node[E]
+	freeLeaf *leaf[E]
+	leaves   []leaf[E]
+	nodes    []node[E]
+	rootNode subtree[E]
+	policy   BoundsPolicy
+	split    SplitStrategy
+	uneven   bool // Whether any leaf has been divided other than at its midpoint
+	maxDepth int
+	minCell  float64
+	gen      uint64
+	key      func(e E) float64
 }
 
 // Returns a new root ready for use as an empty quadtree
@@ -296,19 +381,24 @@ type root struct {
 // 	and managed nodes and leaves. More tree elements can be created and garbage will be garbage
 // 	collected when they are recycled.
 // A root node is initialised and the tree is ready for service.
-func newRoot(view *View, leafAllocation int64) *root {
+func newRoot[E any](view *View, leafAllocation int64) *QuadTree[E] {
 	if leafAllocation < 10 {
 		leafAllocation = 10
 	}
 	leafNum := 3 - ((leafAllocation - 1) % 3) + leafAllocation
 	nodeNum := (leafNum - 1) / 3
-	r := new(root)
-	r.leaves = make([]leaf, leafNum, leafNum)
-	for i := 0; i < len(r.leaves)-2; i++ {
+	r := new(QuadTree[E])
+	r.maxDepth = DefaultMaxDepth
+	r.leaves = make([]leaf[E], leafNum, leafNum)
+	ps := make([]vpoint[E], leafNum*LEAF_SIZE)
+	for i := range r.leaves {
+		r.leaves[i].ps = ps[i*LEAF_SIZE : (i+1)*LEAF_SIZE : (i+1)*LEAF_SIZE]
+	}
+	for i := 0; i < len(r.leaves)-1; i++ {
 		r.leaves[i].nextFree = &r.leaves[i+1]
 	}
-	r.nodes = make([]node, nodeNum, nodeNum)
-	for i := 0; i < len(r.nodes)-2; i++ {
+	r.nodes = make([]node[E], nodeNum, nodeNum)
+	for i := 0; i < len(r.nodes)-1; i++ {
 		r.nodes[i].nextFree = &r.nodes[i+1]
 	}
 	r.freeNode = &r.nodes[0]
@@ -319,12 +409,12 @@ func newRoot(view *View, leafAllocation int64) *root {
 }
 
 // Recursively recycle st and all of its children
-func (r *root) recycle(st subtree) {
+func (r *QuadTree[E]) recycle(st subtree[E]) {
 	switch st.(type) {
-	case *leaf:
-		r.recycleLeaf(st.(*leaf))
-	case *node:
-		r.recycleNode(st.(*node))
+	case *leaf[E]:
+		r.recycleLeaf(st.(*leaf[E]))
+	case *node[E]:
+		r.recycleNode(st.(*node[E]))
 	}
 }
 
@@ -333,35 +423,49 @@ func (r *root) recycle(st subtree) {
 // 	1: A free node from the roots static node array
 //	2: A new node, marked disposable, fresh from the heap
 // We only return 2 if 1 is not available.
-func (r *root) newNode(view *View) (n *node) {
+func (r *QuadTree[E]) newNode(view *View) (n *node[E]) {
+	n = r.allocNode(view)
+	r.newLeaves(view, &n.children)
+	return
+}
+
+// Returns a node, with no children, within the View provided.
+// The node is taken from the same places as newNode.
+func (r *QuadTree[E]) allocNode(view *View) (n *node[E]) {
 	if r.freeNode == nil {
-		n = &node{view: *view, disposable: true}
+		n = &node[E]{view: *view, disposable: true}
 	} else {
 		n = r.freeNode
 		r.freeNode = n.nextFree
 		n.view = *view
 	}
-	r.newLeaves(view, &n.children)
+	n.gen = r.gen
 	return
 }
 
 // Recycles n.
-// If n is disposable this is a no-op, n should be garbage collected
+// If n is shared with a snapshot this is a no-op, see Snapshot
+// Otherwise each of n's children are recycled, they may be static even if n is not.
+// If n is disposable nothing more is done, n should be garbage collected
 // Otherwise n becomes r's next free node. r's old free node becomes 
 // n's next free node.
-// Each of n's children are recycled and n's children array is cleared.
-// n's view is reset.
-func (r *root) recycleNode(n *node) {
-	if n.disposable {
+// n's children array is cleared and n's view is reset.
+func (r *QuadTree[E]) recycleNode(n *node[E]) {
+	if n.gen != r.gen {
 		return
 	}
-	n.nextFree = r.freeNode
-	r.freeNode = n
 	for i := range n.children {
 		r.recycle(n.children[i])
 	}
-	n.children = *new([4]subtree)
+	if n.disposable {
+		return
+	}
+	n.nextFree = r.freeNode
+	r.freeNode = n
+	n.children = *new([4]subtree[E])
 	n.view = *new(View)
+	n.agg = Aggregate{}
+	n.sumX, n.sumY = 0, 0
 }
 
 // Returns a leaf with the view provided.
@@ -369,20 +473,21 @@ func (r *root) recycleNode(n *node) {
 // 	1: A free leaf from the roots static leaf array
 //	2: A new leaf, marked disposable, fresh from the heap
 // We only return 2 if 1 is not available.
-func (r *root) newLeaf(view *View) (l *leaf) {
+func (r *QuadTree[E]) newLeaf(view *View) (l *leaf[E]) {
 	if r.freeLeaf == nil {
-		l = &leaf{view: *view, disposable: true}
+		l = &leaf[E]{view: *view, ps: make([]vpoint[E], LEAF_SIZE), disposable: true, gen: r.gen}
 		return
 	}
 	l = r.freeLeaf
 	r.freeLeaf = l.nextFree
 	l.view = *view
+	l.gen = r.gen
 	return
 }
 
 // Fills the array provided with new leaves each occupying 
 // a quarter of the view provided.
-func (r *root) newLeaves(view *View, leaves *[4]subtree) {
+func (r *QuadTree[E]) newLeaves(view *View, leaves *[4]subtree[E]) {
 	v0, v1, v2, v3 := view.quarters()
 	vs := []*View{v0, v1, v2, v3}
 	for i := range leaves {
@@ -393,47 +498,82 @@ func (r *root) newLeaves(view *View, leaves *[4]subtree) {
 
 // Recycles l.
 // If l is disposable this is a no-op, l should be garbage collected
+// If l is shared with a snapshot this is also a no-op, see Snapshot
 // Otherwise, l becomes r's next free leaf. r's old free leaf becomes 
 // l's next free leaf.
-// l's view is reset. l's array of vpoints is reset.
-func (r *root) recycleLeaf(l *leaf) {
-	if l.disposable {
+// l's view is reset. l's vpoints are reset, an overflowing leaf is cut back to LEAF_SIZE vpoints.
+func (r *QuadTree[E]) recycleLeaf(l *leaf[E]) {
+	if l.disposable || l.gen != r.gen {
 		return
 	}
 	l.nextFree = r.freeLeaf
 	r.freeLeaf = l
 	l.view = *new(View)
-	l.ps = *new([LEAF_SIZE]vpoint)
+	clear(l.ps)
+	l.ps = l.ps[:LEAF_SIZE]
 }
 
 // Inserts the value nval into this tree
-func (r *root) Insert(x, y float64, nval interface{}) {
-	elems := make([]interface{}, 1, 1)
+// If (x,y) lies outside this tree's View the tree's BoundsPolicy decides whether
+// the tree grows to contain it, or nval is not inserted and an error is returned.
+func (r *QuadTree[E]) Insert(x, y float64, nval E) error {
+	if err := r.accommodate(x, y); err != nil {
+		return err
+	}
+	elems := make([]E, 1, 1)
 	elems[0] = nval
-	r.rootNode.insert(x, y, elems, nil, r)
+	r.own(&r.rootNode).insert(x, y, elems, nil, r)
+	return nil
 }
 
 // Dels each element, e, under this node which satisfies two conditions
-// 	1: e lies within view
+// 	1: e lies within reg
 //	2: pred(e) returns true
 // pred may have side-effects allowing for arbitrary processing of deld elements.
-func (r *root) Del(view *View, pred func(x, y float64, e interface{}) bool) {
-	r.rootNode.del(view, pred, nil, r)
+func (r *QuadTree[E]) Del(reg Region, pred func(x, y float64, e E) bool) {
+	r.own(&r.rootNode).del(reg, pred, nil, r)
+}
+
+// Applies fun to every element occurring within any view in vs in this tree
+func (r *QuadTree[E]) Survey(vs []*View, fun func(x, y float64, e E)) {
+	r.rootNode.survey(views(vs), fun)
+}
+
+// Applies fun to every element occurring within reg in this tree
+func (r *QuadTree[E]) SurveyRegion(reg Region, fun func(x, y float64, e E)) {
+	r.rootNode.survey(reg, fun)
+}
+
+// Applies fun to every element occurring within reg in this tree, stopping
+// as soon as fun returns false
+func (r *QuadTree[E]) SurveyUntil(reg Region, fun func(x, y float64, e E) bool) {
+	r.rootNode.surveyUntil(reg, fun)
+}
+
+// Returns an iterator over the location of, and each element occurring within, reg in this tree
+// The tree must not be modified while iterating.
+func (r *QuadTree[E]) Within(reg Region) iter.Seq2[Point, E] {
+	return func(yield func(Point, E) bool) {
+		r.SurveyUntil(reg, func(x, y float64, e E) bool {
+			return yield(Point{x, y}, e)
+		})
+	}
 }
 
-// Applies fun to every element occurring within view in this tree
-func (r *root) Survey(vs []*View, fun func(x, y float64, e interface{})) {
-	r.rootNode.survey(vs, fun)
+// Returns up to k elements ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil
+func (r *QuadTree[E]) Nearest(x, y float64, k int, filter func(x, y float64, e E) bool) []E {
+	return candidateElems(nearestIn([]subtree[E]{r.rootNode}, x, y, k, filter))
 }
 
 // Returns the View for this tree
-func (r *root) View() *View {
+func (r *QuadTree[E]) View() *View {
 	return r.rootNode.View()
 }
 
 // Counts the number of free nodes available.
 // For debugging only
-func (r *root) freeNodes() (cnt int) {
+func (r *QuadTree[E]) freeNodes() (cnt int) {
 	freeNode := r.freeNode
 	for {
 		if freeNode != nil {
@@ -443,12 +583,11 @@ func (r *root) freeNodes() (cnt int) {
 			return
 		}
 	}
-	panic("Unreachable")
 }
 
 // Counts the number of free leaves available.
 // For debugging only
-func (r *root) freeLeaves() (cnt int) {
+func (r *QuadTree[E]) freeLeaves() (cnt int) {
 	freeLeaf := r.freeLeaf
 	for {
 		if freeLeaf != nil {
@@ -458,9 +597,8 @@ func (r *root) freeLeaves() (cnt int) {
 			return
 		}
 	}
-	panic("Unreachable")
 }
 
-func (r *root) String() string {
+func (r *QuadTree[E]) String() string {
 	return r.rootNode.String()
 }
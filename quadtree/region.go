@@ -0,0 +1,197 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// The mean radius of the earth in metres, used to measure great-circle distances
+const earthRadiusMetres = 6367449.0
+
+// A Region is an area which can be surveyed, or deleted from, in a quadtree.
+// A Region must be able to decide whether it contains a point, and whether
+// it overlaps a View. The overlap test is used to prune subtrees which cannot
+// contain any point of the region. It may report false positives, at the cost
+// of visiting subtrees unnecessarily, but never false negatives.
+// A Region must also decide whether it covers a View entirely, which lets a count
+// use the totals held by a subtree without visiting its elements. The cover test
+// may report false negatives, at the cost of descending into the subtree, but
+// never false positives.
+// *View, *Circle, *GeoView, *GeoCircle and *Polygon all implement Region.
+type Region interface {
+	// Indicates whether this region contains the point (x,y)
+	contains(x, y float64) bool
+	// Indicates whether any part of this region may lie within v
+	overlaps(v *View) bool
+	// Indicates whether every point of v lies within this region
+	covers(v *View) bool
+}
+
+// A slice of views used as a single Region, covering the union of the views
+type views []*View
+
+func (vs views) contains(x, y float64) bool {
+	return contains(vs, x, y)
+}
+
+func (vs views) overlaps(v *View) bool {
+	return overlaps(vs, v)
+}
+
+// Only a View covered by a single one of vs is reported as covered
+func (vs views) covers(v *View) bool {
+	return covers(vs, v)
+}
+
+// A Circle is the region of the plane lying within radius of the point (x,y)
+type Circle struct {
+	x, y, radius float64
+}
+
+// Returns a new Circle centred at (x,y)
+// Providing a negative radius will cause a panic
+func NewCircle(x, y, radius float64) *Circle {
+	if radius < 0 {
+		msg := fmt.Sprintf("Cannot create circle with negative radius. radius : %10.3f", radius)
+		panic(msg)
+	}
+	return &Circle{x, y, radius}
+}
+
+// Indicates whether this Circle contains the point (x,y)
+func (c *Circle) contains(x, y float64) bool {
+	return distSq(c.x, c.y, x, y) <= c.radius*c.radius
+}
+
+// Indicates whether any part of this Circle lies within v
+func (c *Circle) overlaps(v *View) bool {
+	return v.distSq(c.x, c.y) <= c.radius*c.radius
+}
+
+// Indicates whether all of v lies within this Circle
+// Because a circle is convex it is enough that it contains each of v's corners.
+func (c *Circle) covers(v *View) bool {
+	return c.contains(v.lx, v.ty) && c.contains(v.rx, v.ty) && c.contains(v.lx, v.by) && c.contains(v.rx, v.by)
+}
+
+// A GeoCircle is the region of the earth's surface lying within a great-circle
+// distance of metres from the point (lat,lng).
+// A quadtree queried with a GeoCircle is expected to store points with
+// x as the latitude and y as the longitude, both in degrees.
+type GeoCircle struct {
+	lat, lng, metres float64
+	bounds           GeoView
+}
+
+// Returns a new GeoCircle centred at (lat,lng)
+// Providing a negative distance will cause a panic
+func NewGeoCircle(lat, lng, metres float64) *GeoCircle {
+	if metres < 0 {
+		msg := fmt.Sprintf("Cannot create geo circle with negative radius. metres : %10.3f", metres)
+		panic(msg)
+	}
+	return &GeoCircle{lat, lng, metres, *NewGeoViewAround(lat, lng, metres)}
+}
+
+// Indicates whether this GeoCircle contains the point (lat,lng)
+func (c *GeoCircle) contains(lat, lng float64) bool {
+	return haversine(c.lat, c.lng, lat, lng) <= c.metres
+}
+
+// Indicates whether any part of this GeoCircle may lie within v
+// This test is made against the bounding GeoView of the circle and
+// so may report overlaps which do not really exist.
+func (c *GeoCircle) overlaps(v *View) bool {
+	return c.bounds.overlaps(v)
+}
+
+// A View's lines of latitude are not great circles, they can bulge out of a
+// GeoCircle even when all four corners lie inside it. So a GeoCircle never
+// reports that it covers a View, and counts always visit the elements within it.
+func (c *GeoCircle) covers(v *View) bool {
+	return false
+}
+
+// Returns the latitude/longitude bounding boxes of the circle of great-circle radius metres centred at (lat,lng).
+// If the circle covers a pole every longitude is included.
+// If the circle crosses the antimeridian the box is split in two, one either side of it.
+func geoBounds(lat, lng, metres float64) []*View {
+	angle := metres / earthRadiusMetres
+	dLat := angle * 180 / math.Pi
+	minLat := lat - dLat
+	maxLat := lat + dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return []*View{NewViewP(math.Max(minLat, -90), math.Min(maxLat, 90), -180, 180)}
+	}
+	ratio := math.Sin(angle) / math.Cos(lat*math.Pi/180)
+	if ratio >= 1 {
+		return []*View{NewViewP(minLat, maxLat, -180, 180)}
+	}
+	dLng := math.Asin(ratio) * 180 / math.Pi
+	minLng := lng - dLng
+	maxLng := lng + dLng
+	if minLng < -180 {
+		return []*View{NewViewP(minLat, maxLat, -180, maxLng), NewViewP(minLat, maxLat, minLng+360, 180)}
+	}
+	if maxLng > 180 {
+		return []*View{NewViewP(minLat, maxLat, minLng, 180), NewViewP(minLat, maxLat, -180, maxLng-360)}
+	}
+	return []*View{NewViewP(minLat, maxLat, minLng, maxLng)}
+}
+
+// Returns the great-circle distance, in metres, between (lat1,lng1) and (lat2,lng2)
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	sLat := math.Sin(dLat / 2)
+	sLng := math.Sin(dLng / 2)
+	a := sLat*sLat + math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*sLng*sLng
+	return 2 * earthRadiusMetres * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// The region of points lying in r but not in or
+type difference struct {
+	r, or Region
+}
+
+// Returns a Region containing every point in r which is not in or
+func Difference(r, or Region) Region {
+	return &difference{r, or}
+}
+
+func (d *difference) contains(x, y float64) bool {
+	return d.r.contains(x, y) && !d.or.contains(x, y)
+}
+
+// It is not, in general, possible to tell whether the difference of two regions
+// overlaps v, so we conservatively test r alone.
+func (d *difference) overlaps(v *View) bool {
+	return d.r.overlaps(v)
+}
+
+func (d *difference) covers(v *View) bool {
+	return d.r.covers(v) && !d.or.overlaps(v)
+}
+
+// The region of points lying in both r and or
+type intersection struct {
+	r, or Region
+}
+
+// Returns a Region containing every point in both r and or
+func Intersection(r, or Region) Region {
+	return &intersection{r, or}
+}
+
+func (i *intersection) contains(x, y float64) bool {
+	return i.r.contains(x, y) && i.or.contains(x, y)
+}
+
+func (i *intersection) overlaps(v *View) bool {
+	return i.r.overlaps(v) && i.or.overlaps(v)
+}
+
+func (i *intersection) covers(v *View) bool {
+	return i.r.covers(v) && i.or.covers(v)
+}
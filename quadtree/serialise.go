@@ -0,0 +1,183 @@
+package quadtree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// Identifies a serialised quadtree, followed by a single version byte
+const (
+	serialMagic   = "QTRE"
+	serialVersion = 1
+)
+
+var FormatErr = errors.New("Not a serialised quadtree, or serialised by an unsupported version")
+
+// A Codec writes and reads elements of type E to and from a serialised quadtree
+type Codec[E any] interface {
+	// Writes e to w
+	Encode(w io.Writer, e E) error
+	// Reads a single element, written by Encode, from r
+	Decode(r io.Reader) (E, error)
+}
+
+// Any quadtree which can be surveyed, both T and *QuadTree[E] qualify
+type surveyable[E any] interface {
+	View() *View
+	Survey(vs []*View, fun func(x, y float64, e E))
+}
+
+// Writes the view of tree, and every element it contains, to w
+// The format is
+//
+//	magic, "QTRE", and a version byte
+//	the view, as four little-endian float64s lx, rx, ty, by
+//	a record for each run of elements surveyed at a single location
+//		the number of elements as a uvarint, then x and y as little-endian float64s
+//		each element as written by codec
+//	a uvarint 0 marking the end of the records
+func WriteTree[E any](w io.Writer, tree surveyable[E], codec Codec[E]) error {
+	sw := &serialWriter{w: bufio.NewWriter(w)}
+	sw.bytes([]byte(serialMagic))
+	sw.bytes([]byte{serialVersion})
+	v := tree.View()
+	sw.float64s(v.lx, v.rx, v.ty, v.by)
+	var x, y float64
+	run := make([]E, 0, 1)
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		sw.uvarint(uint64(len(run)))
+		sw.float64s(x, y)
+		for _, e := range run {
+			if sw.err == nil {
+				sw.err = codec.Encode(sw.w, e)
+			}
+		}
+		run = run[:0]
+	}
+	tree.Survey([]*View{v}, func(ex, ey float64, e E) {
+		if len(run) > 0 && (ex != x || ey != y) {
+			flush()
+		}
+		x, y = ex, ey
+		run = append(run, e)
+	})
+	flush()
+	sw.uvarint(0)
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// Reads a quadtree written by WriteTree from r
+// The tree is bulk loaded, see InsertMany, with the static allocation leafAllocation.
+// The tree returned is Bounded, whatever the policy of the tree that was written.
+func ReadTree[E any](r io.Reader, codec Codec[E], leafAllocation int64) (*QuadTree[E], error) {
+	sr := &serialReader{r: bufio.NewReader(r)}
+	header := sr.bytes(len(serialMagic) + 1)
+	if sr.err != nil {
+		return nil, sr.err
+	}
+	if string(header[:len(serialMagic)]) != serialMagic || header[len(serialMagic)] != serialVersion {
+		return nil, FormatErr
+	}
+	lx, rx, ty, by := sr.float64(), sr.float64(), sr.float64(), sr.float64()
+	if sr.err != nil {
+		return nil, sr.err
+	}
+	if rx < lx || by < ty {
+		return nil, FormatErr
+	}
+	entries := make([]Entry[E], 0)
+	for {
+		n := sr.uvarint()
+		if sr.err != nil {
+			return nil, sr.err
+		}
+		if n == 0 {
+			break
+		}
+		x, y := sr.float64(), sr.float64()
+		for i := uint64(0); i < n && sr.err == nil; i++ {
+			var e E
+			e, sr.err = codec.Decode(sr.r)
+			sr.eof()
+			entries = append(entries, Entry[E]{x, y, e})
+		}
+		if sr.err != nil {
+			return nil, sr.err
+		}
+	}
+	tree := New[E](lx, rx, ty, by, leafAllocation)
+	if err := tree.InsertMany(entries); err != nil {
+		return nil, FormatErr
+	}
+	return tree, nil
+}
+
+// Writes to w until the first error, after which every write is ignored
+type serialWriter struct {
+	w   *bufio.Writer
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (sw *serialWriter) bytes(b []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(b)
+	}
+}
+
+func (sw *serialWriter) uvarint(u uint64) {
+	n := binary.PutUvarint(sw.buf[:], u)
+	sw.bytes(sw.buf[:n])
+}
+
+func (sw *serialWriter) float64s(fs ...float64) {
+	for _, f := range fs {
+		binary.LittleEndian.PutUint64(sw.buf[:8], math.Float64bits(f))
+		sw.bytes(sw.buf[:8])
+	}
+}
+
+// Reads from r until the first error, after which every read returns a zero value
+// Reaching the end of r part way through a quadtree is reported as io.ErrUnexpectedEOF
+type serialReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (sr *serialReader) bytes(n int) []byte {
+	b := make([]byte, n)
+	if sr.err == nil {
+		_, sr.err = io.ReadFull(sr.r, b)
+		sr.eof()
+	}
+	return b
+}
+
+func (sr *serialReader) uvarint() uint64 {
+	if sr.err != nil {
+		return 0
+	}
+	var u uint64
+	u, sr.err = binary.ReadUvarint(sr.r)
+	sr.eof()
+	return u
+}
+
+func (sr *serialReader) float64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(sr.bytes(8)))
+}
+
+func (sr *serialReader) eof() {
+	if sr.err == io.EOF {
+		sr.err = io.ErrUnexpectedEOF
+	}
+}
@@ -0,0 +1,191 @@
+package quadtree
+
+import (
+	"iter"
+)
+
+// A Snapshot is a read-only, point-in-time, copy of a quadtree.
+// Taking a snapshot is cheap, the snapshot shares every node and leaf with the
+// tree it was taken from. Each node and leaf carries the generation of the tree
+// in which it was allocated, and taking a snapshot starts a new generation.
+// Before the tree modifies a node or leaf from an older generation it replaces
+// it with a copy, along with every node on the path down to it. So the snapshot
+// never changes and may be surveyed from any number of goroutines while the
+// tree carries on being modified.
+// The elements themselves are not copied. If the elements are pointers they
+// must not be modified in place while a snapshot is being read.
+// The nodes and leaves which are replaced are left to the garbage collector rather
+// than recycled, as the tree can't tell when a snapshot is no longer used. So a
+// tree which is snapshotted often will gradually move out of its static allocation.
+type Snapshot[E any] struct {
+	view  View
+	roots []subtree[E]
+	key   func(e E) float64
+}
+
+// Returns a snapshot of this tree as it is now
+// The snapshot is unaffected by any later changes to this tree, see Snapshot.
+func (r *QuadTree[E]) Snapshot() *Snapshot[E] {
+	r.gen++
+	return &Snapshot[E]{view: *r.View(), roots: []subtree[E]{r.rootNode}, key: r.key}
+}
+
+// Returns a snapshot holding a copy of every element surveyed by survey
+// survey is called once, with v, and must apply fun to every element within it.
+// Used by indexes which can't share their structure with a snapshot. Every element
+// is copied into a new quadtree over v, sized for n elements.
+func snapshotOf[E any](v *View, n int, survey func(reg Region, fun func(x, y float64, e E))) *Snapshot[E] {
+	entries := make([]Entry[E], 0, n)
+	survey(v, func(x, y float64, e E) {
+		entries = append(entries, Entry[E]{X: x, Y: y, Elem: e})
+	})
+	r := New[E](v.lx, v.rx, v.ty, v.by, int64(len(entries)/6))
+	r.InsertMany(entries)
+	return r.Snapshot()
+}
+
+// Returns the subtree pointed to by st, ready to be modified
+// If the subtree belongs to an older generation, and so may be shared with a
+// snapshot, it is first replaced by a copy in the current generation.
+// The subtree containing st must already have been made ready to modify.
+func (r *QuadTree[E]) own(st *subtree[E]) subtree[E] {
+	switch s := (*st).(type) {
+	case *leaf[E]:
+		if s.gen != r.gen {
+			*st = r.cloneLeaf(s)
+		}
+	case *node[E]:
+		if s.gen != r.gen {
+			*st = r.cloneNode(s)
+		}
+	}
+	return *st
+}
+
+// Returns a copy of l in the current generation
+// The elements at each vpoint are copied too, as deleting from a vpoint
+// rearranges its elements in place.
+func (r *QuadTree[E]) cloneLeaf(l *leaf[E]) *leaf[E] {
+	c := r.newLeaf(l.View())
+	c.ps = append(c.ps[:0], l.ps...)
+	for i := range c.ps {
+		p := &c.ps[i]
+		if !p.zeroed() {
+			p.elems = make([]E, len(l.ps[i].elems))
+			copy(p.elems, l.ps[i].elems)
+		}
+	}
+	return c
+}
+
+// Returns a copy of n in the current generation
+// The copy shares n's children.
+func (r *QuadTree[E]) cloneNode(n *node[E]) *node[E] {
+	c := r.allocNode(n.View())
+	c.children = n.children
+	c.agg = n.agg
+	c.sumX, c.sumY = n.sumX, n.sumY
+	return c
+}
+
+// Returns the View of the tree this snapshot was taken from
+func (s *Snapshot[E]) View() *View {
+	return &s.view
+}
+
+// Applies fun to every element occurring within any view in vs in this snapshot
+func (s *Snapshot[E]) Survey(vs []*View, fun func(x, y float64, e E)) {
+	s.SurveyRegion(views(vs), fun)
+}
+
+// Applies fun to every element occurring within reg in this snapshot
+func (s *Snapshot[E]) SurveyRegion(reg Region, fun func(x, y float64, e E)) {
+	for _, root := range s.roots {
+		if reg.overlaps(root.View()) {
+			root.survey(reg, fun)
+		}
+	}
+}
+
+// Applies fun to every element occurring within reg in this snapshot, stopping
+// as soon as fun returns false
+func (s *Snapshot[E]) SurveyUntil(reg Region, fun func(x, y float64, e E) bool) {
+	for _, root := range s.roots {
+		if reg.overlaps(root.View()) && !root.surveyUntil(reg, fun) {
+			return
+		}
+	}
+}
+
+// Returns an iterator over the location of, and each element occurring within, reg in this snapshot
+func (s *Snapshot[E]) Within(reg Region) iter.Seq2[Point, E] {
+	return func(yield func(Point, E) bool) {
+		s.SurveyUntil(reg, func(x, y float64, e E) bool {
+			return yield(Point{x, y}, e)
+		})
+	}
+}
+
+// Returns the number of elements occurring within any view in vs in this snapshot
+func (s *Snapshot[E]) Count(vs []*View) int {
+	return s.CountRegion(views(vs))
+}
+
+// Returns the number of elements occurring within reg in this snapshot
+func (s *Snapshot[E]) CountRegion(reg Region) int {
+	count := 0
+	for _, root := range s.roots {
+		if reg.overlaps(root.View()) {
+			count += root.aggregate(reg, nil).Count
+		}
+	}
+	return count
+}
+
+// Returns the aggregate of every element occurring within reg in this snapshot
+func (s *Snapshot[E]) AggregateRegion(reg Region) Aggregate {
+	var a Aggregate
+	for _, root := range s.roots {
+		if reg.overlaps(root.View()) {
+			a.merge(root.aggregate(reg, s.key))
+		}
+	}
+	return a
+}
+
+// Returns the number of elements lying within each cell of a grid of xCells by yCells laid over v
+func (s *Snapshot[E]) Heatmap(v *View, xCells, yCells int) [][]int {
+	h := newHeatmap(v, xCells, yCells)
+	for _, root := range s.roots {
+		if v.overlaps(root.View()) {
+			heatmapAdd(h, root)
+		}
+	}
+	return h.counts
+}
+
+// Returns clusters of the elements lying within v, each no wider or taller than resolution
+func (s *Snapshot[E]) Clusters(v *View, resolution float64, reps int) []Cluster[E] {
+	c := newClusterer[E](v, resolution, reps)
+	for _, root := range s.roots {
+		if v.overlaps(root.View()) {
+			c.add(root)
+		}
+	}
+	return c.clusters
+}
+
+// Returns up to k elements ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil
+func (s *Snapshot[E]) Nearest(x, y float64, k int, filter func(x, y float64, e E) bool) []E {
+	return candidateElems(nearestIn(s.roots, x, y, k, filter))
+}
+
+// Returns a human friendly string representation of this snapshot
+func (s *Snapshot[E]) String() string {
+	str := ""
+	for _, root := range s.roots {
+		str += root.String() + "\n"
+	}
+	return str
+}
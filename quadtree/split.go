@@ -0,0 +1,87 @@
+package quadtree
+
+import (
+	"sort"
+)
+
+// Determines where a full leaf is divided when it is replaced by a node
+type SplitStrategy int
+
+const (
+	// A full leaf is divided into four equal quarters at the midpoint of its view
+	MidpointSplit SplitStrategy = iota
+	// A full leaf is divided at the median x and the median y of the points it holds,
+	// so its four children vary in size. Points clustered in a few small areas of a
+	// large view are separated in far fewer divisions than by quartering.
+	MedianSplit
+)
+
+// Sets the strategy used to divide full leaves
+// A new tree uses MidpointSplit. The strategy applies to leaves divided from now on,
+// leaves already divided are unaffected. So a tree may hold nodes divided by either
+// strategy, and the depth limits of SetDepthLimits still count divisions from the root.
+// A growing tree always adds new roots whose quarters are equal, see SetBoundsPolicy.
+func (r *QuadTree[E]) SetSplitStrategy(split SplitStrategy) {
+	r.split = split
+}
+
+// Returns a node, with four new leaves, dividing the view v according to this tree's split strategy
+// ps, and extra, are the points about to be inserted into the node. Zeroed vpoints are ignored.
+func (r *QuadTree[E]) newSplitNode(v *View, ps []vpoint[E], extra ...vpoint[E]) *node[E] {
+	if r.split != MedianSplit {
+		return r.newNode(v)
+	}
+	xs := make([]float64, 0, len(ps)+len(extra))
+	ys := make([]float64, 0, len(ps)+len(extra))
+	for _, pss := range [][]vpoint[E]{ps, extra} {
+		for i := range pss {
+			if !pss[i].zeroed() {
+				xs = append(xs, pss[i].x)
+				ys = append(ys, pss[i].y)
+			}
+		}
+	}
+	r.uneven = true
+	n := r.allocNode(v)
+	v0, v1, v2, v3 := v.divide(medianSplit(xs, v.lx, v.rx, r.minCell), medianSplit(ys, v.ty, v.by, r.minCell))
+	for i, cv := range []*View{v0, v1, v2, v3} {
+		n.children[i] = r.newLeaf(cv)
+	}
+	return n
+}
+
+// Returns the coordinate, lying strictly between lo and hi, at which to divide cs
+// cs are divided between their two middle values, so that about half of them lie on
+// either side. A point on the dividing line belongs to the first child containing it, so
+// the line is moved down to the lower middle value if rounding would put it on the upper.
+// Where the middle values are equal the nearest distinct pair of values is used instead.
+// The line is kept minCell away from lo and hi. If it can't be, or cs are all equal,
+// the midpoint of lo and hi is returned.
+// cs is sorted in place.
+func medianSplit(cs []float64, lo, hi, minCell float64) float64 {
+	mid := lo + (hi-lo)/2
+	sort.Float64s(cs)
+	k := -1
+	for d := 0; d < len(cs); d++ {
+		if i := len(cs)/2 - d; i > 0 && cs[i-1] < cs[i] {
+			k = i
+			break
+		}
+		if i := len(cs)/2 + d; i > 0 && i < len(cs) && cs[i-1] < cs[i] {
+			k = i
+			break
+		}
+	}
+	if k < 0 {
+		return mid
+	}
+	c := cs[k-1] + (cs[k]-cs[k-1])/2
+	if c >= cs[k] {
+		c = cs[k-1]
+	}
+	c = min(max(c, lo+minCell), hi-minCell)
+	if !(lo < c && c < hi) {
+		return mid
+	}
+	return c
+}
@@ -0,0 +1,90 @@
+package quadtree
+
+import (
+	"fmt"
+)
+
+// A snapshot of the shape and contents of a quadtree
+type Stats struct {
+	// The number of nodes and leaves in the tree
+	Nodes, Leaves int
+	// The number of nodes and leaves in the tree allocated from the heap,
+	// rather than the static arrays, because the free lists were exhausted
+	DisposableNodes, DisposableLeaves int
+	// The number of unused nodes and leaves left in the static arrays
+	FreeNodes, FreeLeaves int
+	// The number of distinct locations at which elements are stored
+	Points int
+	// The number of elements stored
+	Elems int
+	// LeafDepths[d] is the number of leaves at depth d, the root node lies at depth 0
+	LeafDepths []int
+	// Occupancy[i] is the number of leaves holding exactly i points
+	Occupancy [LEAF_SIZE + 1]int
+	// The number of leaves holding more than LEAF_SIZE points, see SetDepthLimits.
+	// These are not included in Occupancy.
+	OverflowLeaves int
+}
+
+// Indicates whether the static node or leaf arrays have run out, and new nodes or
+// leaves will be allocated from the heap
+func (s *Stats) PoolExhausted() bool {
+	return s.FreeNodes == 0 || s.FreeLeaves == 0
+}
+
+// Returns the depth of the deepest leaf in the tree
+func (s *Stats) MaxDepth() int {
+	return len(s.LeafDepths) - 1
+}
+
+// Human readable summary of s
+func (s *Stats) String() string {
+	return fmt.Sprintf("nodes: %d (%d disposable, %d free) leaves: %d (%d disposable, %d free) points: %d elems: %d max depth: %d leaf depths: %v occupancy: %v overflowing: %d",
+		s.Nodes, s.DisposableNodes, s.FreeNodes, s.Leaves, s.DisposableLeaves, s.FreeLeaves, s.Points, s.Elems, s.MaxDepth(), s.LeafDepths, s.Occupancy, s.OverflowLeaves)
+}
+
+// Returns statistics describing the current shape and contents of this tree
+// Every node and leaf in the tree is visited.
+func (r *QuadTree[E]) Stats() Stats {
+	s := Stats{FreeNodes: r.freeNodes(), FreeLeaves: r.freeLeaves()}
+	gatherStats(r.rootNode, 0, &s)
+	return s
+}
+
+// Adds st, and each of its descendants, to s
+func gatherStats[E any](st subtree[E], depth int, s *Stats) {
+	switch st.(type) {
+	case *leaf[E]:
+		l := st.(*leaf[E])
+		s.Leaves++
+		if l.disposable {
+			s.DisposableLeaves++
+		}
+		for len(s.LeafDepths) <= depth {
+			s.LeafDepths = append(s.LeafDepths, 0)
+		}
+		s.LeafDepths[depth]++
+		points := 0
+		for i := range l.ps {
+			if !l.ps[i].zeroed() {
+				points++
+				s.Elems += len(l.ps[i].elems)
+			}
+		}
+		s.Points += points
+		if points > LEAF_SIZE {
+			s.OverflowLeaves++
+		} else {
+			s.Occupancy[points]++
+		}
+	case *node[E]:
+		n := st.(*node[E])
+		s.Nodes++
+		if n.disposable {
+			s.DisposableNodes++
+		}
+		for i := range n.children {
+			gatherStats(n.children[i], depth+1, s)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// Tests that counting a region finds as many elements as surveying it, after inserts, deletes and moves
+func TestCount(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testCount(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testCount(tree, t)
+	}
+}
+
+func testCount(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	testCountRegions(tree, "Count after insert", t)
+	tree.Del(subView(tree.View()), SimpleDelete())
+	testCountRegions(tree, "Count after delete", t)
+	for i := 0; i < len(ps); i += 3 {
+		x, y := randomPosition(tree.View())
+		tree.Move(ps[i].x, ps[i].y, x, y, func(_, _ float64, _ interface{}) bool { return true })
+	}
+	testCountRegions(tree, "Count after move", t)
+	if count := tree.Count([]*View{tree.View()}); count != countSurvey(tree, views{tree.View()}) {
+		t.Errorf("Count of whole tree, expecting %d found %d", countSurvey(tree, views{tree.View()}), count)
+	}
+}
+
+func testCountRegions(tree T, errPfx string, t *testing.T) {
+	for i := 0; i < 20; i++ {
+		for _, reg := range []Region{views{subView(tree.View()), subView(tree.View())}, randomCircle(tree.View()), testPolygon(tree.View())} {
+			expected := countSurvey(tree, reg)
+			if count := tree.CountRegion(reg); count != expected {
+				t.Errorf("%s %v, expecting %d elements found %d", errPfx, reg, expected, count)
+			}
+		}
+	}
+}
+
+func countSurvey(tree T, reg Region) int {
+	count := 0
+	tree.SurveyRegion(reg, func(_, _ float64, _ interface{}) {
+		count++
+	})
+	return count
+}
+
+// Tests that points lying on the borders between subtrees are counted once, by every node above them
+func TestCountBorders(t *testing.T) {
+	tree := NewQuadTree(0, 100, 0, 100, treeLim)
+	count := 0
+	for x := 0.0; x <= 100; x += 12.5 {
+		for y := 0.0; y <= 100; y += 12.5 {
+			tree.Insert(x, y, count)
+			count++
+		}
+	}
+	if found := tree.Count([]*View{tree.View()}); found != count {
+		t.Errorf("Count of border points, expecting %d found %d", count, found)
+	}
+	testCountRegions(tree, "Count of border points", t)
+}
+
+// Tests that the sum, min and max of the elements within a region match those found by surveying it
+func TestAggregate(t *testing.T) {
+	tree := New[int](0, 1000, 0, 1000, treeLim)
+	tree.SetBoundsPolicy(Growing)
+	ps := fillView(tree.View(), 500)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	// Setting the key after the tree is filled must recalculate every node
+	tree.SetAggregateKey(func(e int) float64 { return float64(e) })
+	testAggregate(tree, "Aggregate after setting key", t)
+	entries := make([]Entry[int], 500)
+	for i := range entries {
+		x, y := randomPosition(tree.View())
+		entries[i] = Entry[int]{x, y, -i}
+	}
+	tree.InsertMany(entries)
+	testAggregate(tree, "Aggregate after insert many", t)
+	snap := tree.Snapshot()
+	expected := tree.AggregateRegion(tree.View())
+	tree.Del(subView(tree.View()), func(_, _ float64, e int) bool { return e%2 == 0 })
+	testAggregate(tree, "Aggregate after delete", t)
+	tree.Insert(-500.5, 2000.5, 10000)
+	testAggregate(tree, "Aggregate after growing", t)
+	if found := snap.AggregateRegion(snap.View()); found != expected {
+		t.Errorf("Aggregate of snapshot, expecting %v found %v", expected, found)
+	}
+}
+
+func testAggregate(tree *QuadTree[int], errPfx string, t *testing.T) {
+	regs := []Region{tree.View()}
+	for i := 0; i < 20; i++ {
+		regs = append(regs, subView(tree.View()), randomCircle(tree.View()))
+	}
+	for _, reg := range regs {
+		var expected Aggregate
+		tree.SurveyRegion(reg, func(_, _ float64, e int) {
+			expected.merge(Aggregate{1, float64(e), float64(e), float64(e)})
+		})
+		if found := tree.AggregateRegion(reg); found != expected {
+			t.Errorf("%s %v, expecting %v found %v", errPfx, reg, expected, found)
+		}
+		if count := tree.CountRegion(reg); count != expected.Count {
+			t.Errorf("%s %v, expecting count %d found %d", errPfx, reg, expected.Count, count)
+		}
+	}
+}
+
+// Tests that no region claims to cover a view containing a point outside the region
+func TestRegionCovers(t *testing.T) {
+	v := OrigViewP(100, 100)
+	for i := 0; i < 1000; i++ {
+		sv := subView(v)
+		regs := []Region{randomCircle(v), testPolygon(v), views{subView(v)}, Difference(randomCircle(v), randomCircle(v)), Intersection(randomCircle(v), randomCircle(v))}
+		for _, reg := range regs {
+			if !reg.covers(sv) {
+				continue
+			}
+			for _, p := range append(fillView(sv, 20), point{sv.lx, sv.ty}, point{sv.rx, sv.by}) {
+				if !reg.contains(p.x, p.y) {
+					t.Errorf("Region %v covers %v but doesn't contain (%f,%f)", reg, sv, p.x, p.y)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package quadtree
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Tests that a bounded tree rejects points outside its view, leaving the tree unchanged
+func TestBoundedInsert(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testBoundedInsert(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testBoundedInsert(tree, t)
+	}
+}
+
+func testBoundedInsert(tree T, t *testing.T) {
+	v := tree.View()
+	ps := fillView(v, 1000)
+	for _, p := range ps {
+		if err := tree.Insert(p.x, p.y, "test"); err != nil {
+			t.Errorf("Bounded insert, unexpected error inserting (%f,%f) into %v: %v", p.x, p.y, v, err)
+		}
+	}
+	outside := []point{{v.lx - 1, v.ty}, {v.rx + 1, v.by}, {v.lx, v.ty - 1}, {v.rx, v.by + 1}, {math.NaN(), v.ty}}
+	for _, p := range outside {
+		if err := tree.Insert(p.x, p.y, "outside"); !errors.Is(err, OutOfBoundsErr) {
+			t.Errorf("Bounded insert (%f,%f) into %v, expecting OutOfBoundsErr found %v", p.x, p.y, v, err)
+		}
+	}
+	if err := tree.Move(ps[0].x, ps[0].y, v.rx+1, v.by+1, func(_, _ float64, _ interface{}) bool { return true }); !errors.Is(err, OutOfBoundsErr) {
+		t.Errorf("Bounded move out of %v, expecting OutOfBoundsErr found %v", v, err)
+	}
+	if !tree.View().eq(v) {
+		t.Errorf("Bounded insert, expecting view %v found %v", v, tree.View())
+	}
+	testSurveyScatter(tree, ps, 1, "Bounded insert", t)
+}
+
+// Tests that a growing tree accommodates points far outside its original view
+func TestGrowingInsert(t *testing.T) {
+	tree := New[interface{}](0, 100, 0, 100, treeLim)
+	tree.SetBoundsPolicy(Growing)
+	ps := fillView(tree.View(), 1000)
+	for _, p := range ps {
+		tree.Insert(p.x, p.y, "test")
+	}
+	for _, v := range []*View{NewViewP(-1e6, 0, -1e6, 0), NewViewP(100, 1e5, -50, 50), NewViewP(-10, 1e7, 100, 1e3)} {
+		for _, p := range fillView(v, 200) {
+			if err := tree.Insert(p.x, p.y, "test"); err != nil {
+				t.Errorf("Growing insert, unexpected error inserting (%f,%f): %v", p.x, p.y, err)
+			}
+			ps = append(ps, p)
+		}
+	}
+	for _, p := range ps {
+		if !tree.View().contains(p.x, p.y) {
+			t.Errorf("Growing insert, expecting view %v to contain (%f,%f)", tree.View(), p.x, p.y)
+		}
+	}
+	testSurveyScatter(tree, ps, 1, "Growing insert", t)
+	if err := tree.Insert(math.Inf(1), 0, "test"); !errors.Is(err, OutOfBoundsErr) {
+		t.Errorf("Growing insert at infinity, expecting OutOfBoundsErr found %v", err)
+	}
+}
+
+// Tests that a growing tree accommodates elements moved outside its original view
+func TestGrowingMove(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	tree.SetBoundsPolicy(Growing)
+	for i := 0; i < 100; i++ {
+		tree.Insert(float64(i)+0.3, float64(i)+0.3, i)
+	}
+	for i := 0; i < 100; i++ {
+		moving := i
+		if err := tree.Move(float64(i)+0.3, float64(i)+0.3, -float64(i)*1000.3, float64(i)*1000.3, func(_, _ float64, e int) bool { return e == moving }); err != nil {
+			t.Errorf("Growing move, unexpected error moving %d: %v", i, err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		found := 0
+		tree.Survey([]*View{PointViewP(-float64(i)*1000.3, float64(i)*1000.3)}, func(_, _ float64, e int) {
+			if e == i {
+				found++
+			}
+		})
+		if found != 1 {
+			t.Errorf("Growing move, expecting element %d once at its new location, found %d times", i, found)
+		}
+	}
+}
+
+// Tests that InsertMany inserts every entry within a bounded tree, and reports those it rejects
+func TestBoundedInsertMany(t *testing.T) {
+	tree := New[interface{}](0, 100, 0, 100, treeLim)
+	ps := fillView(tree.View(), 1000)
+	entries := make([]Entry[interface{}], 0, len(ps)+2)
+	for _, p := range ps {
+		entries = append(entries, Entry[interface{}]{p.x, p.y, "test"})
+	}
+	entries = append(entries, Entry[interface{}]{-1, 50, "outside"}, Entry[interface{}]{50, 101, "outside"})
+	if err := tree.InsertMany(entries); !errors.Is(err, OutOfBoundsErr) {
+		t.Errorf("Bounded insert many, expecting OutOfBoundsErr found %v", err)
+	}
+	testSurveyScatter(tree, ps, 1, "Bounded insert many", t)
+}
@@ -0,0 +1,84 @@
+package quadtree
+
+import (
+	"strconv"
+	"testing"
+)
+
+// Returns an entry for each point in ps, with dups entries at each point
+func makeEntries(ps []point, dups int) []Entry[interface{}] {
+	entries := make([]Entry[interface{}], 0, len(ps)*dups)
+	for i, p := range ps {
+		for d := 0; d < dups; d++ {
+			entries = append(entries, Entry[interface{}]{p.x, p.y, strconv.Itoa(i) + "_" + strconv.Itoa(d)})
+		}
+	}
+	return entries
+}
+
+// Tests that a bulk loaded tree can be surveyed using random views
+func TestBulkLoad(t *testing.T) {
+	for _, tree := range testTrees {
+		v := tree.View()
+		ps := fillView(v, 1000)
+		bulk := NewBulk(v.lx, v.rx, v.ty, v.by, treeLim, makeEntries(ps, dups))
+		testSurveyScatter(bulk, ps, dups, "Bulk load", t)
+	}
+}
+
+// Tests that InsertMany can add entries to a tree which already contains elements,
+// including entries at the same location as existing elements
+func TestInsertMany(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		ps := fillView(tree.View(), 1000)
+		for _, p := range ps[:500] {
+			tree.Insert(p.x, p.y, "single")
+		}
+		entries := makeEntries(ps[:500], 1)
+		entries = append(entries, makeEntries(ps[500:], 2)...)
+		tree.(*QuadTree[interface{}]).InsertMany(entries)
+		testSurveyScatter(tree, ps, 2, "Insert many", t)
+	}
+}
+
+// Tests that DelMany deletes exactly those elements at the points given
+func TestDelMany(t *testing.T) {
+	for _, tree := range testTrees {
+		v := tree.View()
+		ps := fillView(v, 1000)
+		bulk := NewBulk(v.lx, v.rx, v.ty, v.by, treeLim, makeEntries(ps, dups))
+		delPs := make([]Point, 0, len(ps)/2)
+		for _, p := range ps[:len(ps)/2] {
+			delPs = append(delPs, Point{p.x, p.y})
+		}
+		pred, deleted := CollectingDelete()
+		bulk.DelMany(delPs, pred)
+		if deleted.Len() != len(delPs)*dups {
+			t.Errorf("Delete many, expecting %d deleted elements found %d", len(delPs)*dups, deleted.Len())
+		}
+		testSurveyScatter(bulk, ps[len(ps)/2:], dups, "Delete many", t)
+	}
+}
+
+// Surveys tree with random views, and the whole of its view, expecting to find
+// exactly dups elements at each of ps lying within the view
+func testSurveyScatter(tree T, ps []point, dups int, errPfx string, t *testing.T) {
+	vs := []*View{tree.View()}
+	for i := 0; i < 100; i++ {
+		vs = append(vs, subView(tree.View()))
+	}
+	for _, sv := range vs {
+		var count int
+		for _, p := range ps {
+			if sv.contains(p.x, p.y) {
+				count++
+			}
+		}
+		fun, results := SimpleSurvey()
+		tree.Survey([]*View{sv}, fun)
+		if count*dups != results.Len() {
+			t.Errorf("%s: Expecting %d elements in %v, found %d", errPfx, count*dups, sv, results.Len())
+		}
+	}
+}
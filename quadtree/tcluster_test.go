@@ -0,0 +1,87 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+)
+
+// Tests that clusters account for every element in the view, and that each cluster's
+// representatives lie close to its centroid
+func TestClusters(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testClusters(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testClusters(tree, t)
+	}
+}
+
+func testClusters(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 5000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for _, v := range []*View{tree.View(), subView(tree.View())} {
+		expected := tree.Count([]*View{v})
+		for _, div := range []float64{1, 10, 100, 1000} {
+			resolution := math.Max(v.width(), v.height()) / div
+			if resolution == 0 {
+				continue
+			}
+			reps := int(div) % 7
+			clusters := tree.Clusters(v, resolution, reps)
+			count := 0
+			for _, c := range clusters {
+				count += c.Count
+				if !v.contains(c.X, c.Y) {
+					t.Errorf("Clusters of %v, centroid (%f,%f) lies outside the view", v, c.X, c.Y)
+				}
+				if len(c.Elems) != min(reps, c.Count) {
+					t.Errorf("Clusters of %v, expecting %d representatives found %d", v, min(reps, c.Count), len(c.Elems))
+				}
+				for _, e := range c.Elems {
+					p := ps[e.(int)]
+					if !v.contains(p.x, p.y) || math.Sqrt(distSq(p.x, p.y, c.X, c.Y)) > resolution*math.Sqrt2 {
+						t.Errorf("Clusters of %v at resolution %f, representative (%f,%f) too far from centroid (%f,%f)", v, resolution, p.x, p.y, c.X, c.Y)
+					}
+				}
+			}
+			if count != expected {
+				t.Errorf("Clusters of %v at resolution %f, expecting %d elements found %d", v, resolution, expected, count)
+			}
+		}
+	}
+}
+
+// Tests that a node small enough to be a single cluster has the centroid of its elements,
+// including after some of them are moved within a subtree below the root
+func TestClusterCentroid(t *testing.T) {
+	tree := New[int](0, 1000, 0, 1000, treeLim)
+	sx, sy := 0.0, 0.0
+	ps := fillView(NewViewP(0, 100, 0, 100), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+		sx += p.x
+		sy += p.y
+	}
+	testClusterCentroid(tree, sx, sy, "Cluster centroid", t)
+	for i := 0; i < len(ps); i += 3 {
+		x, y := randomPosition(NewViewP(0, 100, 0, 100))
+		tree.Move(ps[i].x, ps[i].y, x, y, func(_, _ float64, e int) bool { return e == i })
+		sx += x - ps[i].x
+		sy += y - ps[i].y
+	}
+	testClusterCentroid(tree, sx, sy, "Cluster centroid after move", t)
+}
+
+func testClusterCentroid(tree *QuadTree[int], sx, sy float64, errPfx string, t *testing.T) {
+	clusters := tree.Clusters(tree.View(), 1000, 1)
+	if len(clusters) != 1 {
+		t.Fatalf("%s, expecting a single cluster found %v", errPfx, clusters)
+	}
+	c := clusters[0]
+	if c.Count != 1000 || math.Abs(c.X-sx/1000) > 1e-9 || math.Abs(c.Y-sy/1000) > 1e-9 {
+		t.Errorf("%s, expecting 1000 elements at (%f,%f) found %v", errPfx, sx/1000, sy/1000, c)
+	}
+}
@@ -0,0 +1,96 @@
+package quadtree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+const (
+	hammerWriters = 8
+	hammerReaders = 8
+	hammerOps     = 2000
+)
+
+func makeConcurrentTrees() []T {
+	trees := make([]T, len(testTrees))
+	for i, tree := range testTrees {
+		v := tree.View()
+		trees[i] = NewConcurrentQuadTree(v.lx, v.rx, v.ty, v.by, treeLim)
+	}
+	return trees
+}
+
+// Tests that a concurrent quadtree behaves exactly like a plain quadtree when used from a single goroutine
+func TestConcurrentSequential(t *testing.T) {
+	for _, tree := range makeConcurrentTrees() {
+		testScatter(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testScatterDelete(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testNearest(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testCircleSurvey(tree, t)
+	}
+}
+
+// Hammers a concurrent quadtree with inserts, deletes, surveys and nearest neighbour
+// searches from many goroutines at once. Intended to be run with the race detector.
+// Each writer inserts uniquely named elements and deletes every second one, so once
+// all writers have finished the tree must contain exactly the elements never deleted.
+func TestConcurrentHammer(t *testing.T) {
+	tree := NewConcurrentQuadTree(0, 100, 0, 100, treeLim)
+	var wg sync.WaitGroup
+	for w := 0; w < hammerWriters; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < hammerOps; i++ {
+				x, y := rnd.Float64()*100, rnd.Float64()*100
+				name := hammerName{w, i}
+				tree.Insert(x, y, name)
+				if i%2 == 1 {
+					tree.Del(PointViewP(x, y), func(_, _ float64, e interface{}) bool {
+						return e == name
+					})
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < hammerReaders; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(-r)))
+			for i := 0; i < hammerOps/10; i++ {
+				x, y := rnd.Float64()*100, rnd.Float64()*100
+				if i%2 == 0 {
+					fun, _ := SimpleSurvey()
+					tree.SurveyRegion(NewCircle(x, y, rnd.Float64()*50), fun)
+				} else {
+					tree.Nearest(x, y, 10, nil)
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+	fun, results := SimpleSurvey()
+	tree.Survey([]*View{tree.View()}, fun)
+	if exp := hammerWriters * hammerOps / 2; results.Len() != exp {
+		t.Errorf("Concurrent hammer, expecting %d elements found %d", exp, results.Len())
+	}
+	for e := results.Front(); e != nil; e = e.Next() {
+		if name := e.Value.(hammerName); name.i%2 == 1 {
+			t.Errorf("Concurrent hammer, found deleted element %v", name)
+		}
+	}
+}
+
+// Uniquely identifies an element inserted by a hammering writer
+type hammerName struct {
+	w, i int
+}
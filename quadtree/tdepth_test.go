@@ -0,0 +1,164 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns count distinct points packed into a square, spread wide, around (x,y)
+func crowd(x, y, spread float64, count int) []point {
+	ps := make([]point, count)
+	for i := range ps {
+		ps[i] = point{x: x + testRand.Float64()*spread, y: y + testRand.Float64()*spread}
+	}
+	return ps
+}
+
+// Returns the leaves of the subtree st, left to right
+func leavesOf[E any](st subtree[E]) []*leaf[E] {
+	switch st.(type) {
+	case *leaf[E]:
+		return []*leaf[E]{st.(*leaf[E])}
+	case *node[E]:
+		n := st.(*node[E])
+		var ls []*leaf[E]
+		for i := range n.children {
+			ls = append(ls, leavesOf(n.children[i])...)
+		}
+		return ls
+	}
+	return nil
+}
+
+// Tests that a crowd of points packed closer together than the tree's maximum depth can
+// separate them is held in a single overflowing leaf, and can be surveyed and deleted
+func TestMaxDepthCrowd(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	tree.SetDepthLimits(8, 0)
+	ps := crowd(40, 60, 1e-6, 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	s := tree.Stats()
+	if s.MaxDepth() > 8 {
+		t.Errorf("Crowd, expecting a max depth of 8, found %d", s.MaxDepth())
+	}
+	if s.OverflowLeaves != 1 || s.Points != len(ps) {
+		t.Errorf("Crowd, expecting 1 overflowing leaf holding %d points %v", len(ps), s.String())
+	}
+	testStats(s, len(ps), len(ps), t)
+	found := make(map[int]bool)
+	tree.Survey([]*View{NewViewP(40, 41, 60, 61)}, func(x, y float64, e int) {
+		if ps[e] != (point{x, y}) {
+			t.Errorf("Crowd, found element %d at (%f,%f), expecting (%f,%f)", e, x, y, ps[e].x, ps[e].y)
+		}
+		found[e] = true
+	})
+	if len(found) != len(ps) {
+		t.Errorf("Crowd, expecting to survey %d elements, found %d", len(ps), len(found))
+	}
+	tree.Del(tree.View(), func(_, _ float64, e int) bool {
+		return e%2 == 0
+	})
+	if c := tree.Count([]*View{tree.View()}); c != len(ps)/2 {
+		t.Errorf("Crowd, expecting %d elements after deleting half, counted %d", len(ps)/2, c)
+	}
+	tree.Del(tree.View(), func(_, _ float64, _ int) bool { return true })
+	for _, l := range leavesOf(tree.rootNode) {
+		if len(l.ps) != LEAF_SIZE {
+			t.Errorf("Crowd, expecting an emptied leaf %v to hold %d vpoints, found %d", &l.view, LEAF_SIZE, len(l.ps))
+		}
+	}
+}
+
+// Tests that no leaf is divided into quarters smaller than the tree's minimum cell size
+func TestMinCellSize(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	tree.SetDepthLimits(DefaultMaxDepth, 1)
+	ps := append(crowd(20, 20, 0.5, 500), fillView(tree.View(), 500)...)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for _, l := range leavesOf(tree.rootNode) {
+		if l.view.width() < 1 || l.view.height() < 1 {
+			t.Errorf("Min cell size, expecting no leaf smaller than 1, found %v", &l.view)
+		}
+	}
+	if c := tree.Count([]*View{tree.View()}); c != len(ps) {
+		t.Errorf("Min cell size, expecting %d elements, counted %d", len(ps), c)
+	}
+}
+
+// Tests that points lying on neighbouring floating point numbers, with no depth limit,
+// are divided only until quartering no longer produces smaller views
+func TestPrecisionCollapse(t *testing.T) {
+	tree := New[int](0, 1, 0, 1, treeLim)
+	tree.SetDepthLimits(math.MaxInt, 0)
+	x := 0.5
+	for i := 0; i < 100; i++ {
+		tree.Insert(x, x, i)
+		x = math.Nextafter(x, 1)
+	}
+	if c := tree.Count([]*View{tree.View()}); c != 100 {
+		t.Errorf("Precision collapse, expecting 100 elements, counted %d", c)
+	}
+	if s := tree.Stats(); s.MaxDepth() > 53 {
+		t.Errorf("Precision collapse, expecting a max depth no more than 53 %v", s.String())
+	}
+	ulp := NewViewP(0.5, math.Nextafter(0.5, 1), 0.5, math.Nextafter(0.5, 1))
+	if tree.canDivide(ulp) {
+		t.Errorf("Precision collapse, expecting %v not to be divided", ulp)
+	}
+}
+
+// Tests that bulk loading a crowd respects the tree's depth limits
+func TestBulkCrowd(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	tree.SetDepthLimits(6, 0)
+	ps := crowd(70, 30, 1e-3, 500)
+	entries := make([]Entry[int], len(ps))
+	for i, p := range ps {
+		entries[i] = Entry[int]{X: p.x, Y: p.y, Elem: i}
+	}
+	tree.InsertMany(entries)
+	s := tree.Stats()
+	if s.MaxDepth() > 6 || s.OverflowLeaves != 1 {
+		t.Errorf("Bulk crowd, expecting 1 overflowing leaf no deeper than 6 %v", s.String())
+	}
+	testStats(s, len(ps), len(ps), t)
+}
+
+// Tests that a snapshot is unaffected by deleting from an overflowing leaf it shares
+func TestSnapshotCrowd(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	tree.SetDepthLimits(4, 0)
+	ps := crowd(10, 10, 1e-3, 200)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	snap := tree.Snapshot()
+	tree.Del(tree.View(), func(_, _ float64, _ int) bool { return true })
+	if c := snap.Count([]*View{snap.View()}); c != len(ps) {
+		t.Errorf("Snapshot crowd, expecting %d elements in the snapshot, counted %d", len(ps), c)
+	}
+	if c := tree.Count([]*View{tree.View()}); c != 0 {
+		t.Errorf("Snapshot crowd, expecting no elements in the tree, counted %d", c)
+	}
+}
+
+// Tests that invalid depth limits are rejected
+func TestBadDepthLimits(t *testing.T) {
+	for _, lim := range []struct {
+		maxDepth int
+		minCell  float64
+	}{{0, 0}, {-1, 0}, {8, -1}, {8, math.NaN()}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expecting a panic for depth limits %d and %f", lim.maxDepth, lim.minCell)
+				}
+			}()
+			New[int](0, 10, 0, 10, treeLim).SetDepthLimits(lim.maxDepth, lim.minCell)
+		}()
+	}
+}
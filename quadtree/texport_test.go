@@ -0,0 +1,131 @@
+package quadtree
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+// Returns a tree over the globe, with x as latitude and y as longitude, holding count random users
+// and a crowd of 100 points packed closer than its maximum depth can separate
+func walkTree(count int) *QuadTree[int] {
+	tree := New[int](-90, 90, -180, 180, treeLim)
+	tree.SetDepthLimits(6, 0)
+	ps := append(fillView(tree.View(), count), crowd(10, 20, 1e-3, 100)...)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	return tree
+}
+
+// Tests that walking a tree visits every node, leaf and element counted by Stats
+func TestWalk(t *testing.T) {
+	tree := walkTree(1000)
+	s := tree.Stats()
+	nodes, leaves, elems, maxDepth := 0, 0, 0, 0
+	tree.Walk(func(tn *TreeNode[int]) bool {
+		maxDepth = max(maxDepth, tn.Depth)
+		if !tn.Leaf {
+			nodes++
+			return true
+		}
+		leaves++
+		elems += len(tn.Entries)
+		for _, e := range tn.Entries {
+			if !tn.View.contains(e.X, e.Y) {
+				t.Errorf("Walk, leaf %v holds element %d at (%f,%f) outside it", &tn.View, e.Elem, e.X, e.Y)
+			}
+		}
+		return true
+	})
+	if nodes != s.Nodes || leaves != s.Leaves || elems != s.Elems || maxDepth != s.MaxDepth() {
+		t.Errorf("Walk, visited %d nodes, %d leaves and %d elems to depth %d %v", nodes, leaves, elems, maxDepth, s.String())
+	}
+	visited := 0
+	tree.Snapshot().Walk(func(tn *TreeNode[int]) bool {
+		visited++
+		return tn.Depth < 1
+	})
+	if visited != 5 {
+		t.Errorf("Walk, expecting to visit the root and its 4 children only, visited %d", visited)
+	}
+}
+
+// Tests that the SVG drawing of a tree is well formed and draws every node, leaf and location
+func TestWriteSVG(t *testing.T) {
+	tree := walkTree(500)
+	s := tree.Stats()
+	var buf bytes.Buffer
+	if err := WriteSVG[int](&buf, tree, 800); err != nil {
+		t.Fatalf("SVG, writing failed %v", err)
+	}
+	classes := make(map[string]int)
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("SVG, malformed drawing %v", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			for _, a := range se.Attr {
+				if a.Name.Local == "class" {
+					classes[a.Value]++
+				}
+			}
+		}
+	}
+	if classes["node"] != s.Nodes || classes["leaf"]+classes["overflow"] != s.Leaves || classes["point"] != s.Points || classes["overflow"] != s.OverflowLeaves {
+		t.Errorf("SVG, drew %v %v", classes, s.String())
+	}
+}
+
+// Tests that the GeoJSON of a tree holds every node, leaf and location, with longitude first
+func TestWriteGeoJSON(t *testing.T) {
+	tree := New[int](-90, 90, -180, 180, treeLim)
+	tree.Insert(-33.87, 151.21, 1)
+	tree.Insert(-33.87, 151.21, 2)
+	var buf bytes.Buffer
+	if err := WriteGeoJSON[int](&buf, tree.Snapshot()); err != nil {
+		t.Fatalf("GeoJSON, writing failed %v", err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fc); err != nil {
+		t.Fatalf("GeoJSON, malformed %v", err)
+	}
+	kinds := make(map[string]int)
+	for _, f := range fc.Features {
+		switch f.Geometry.Type {
+		case "Point":
+			var c [2]float64
+			json.Unmarshal(f.Geometry.Coordinates, &c)
+			if c != [2]float64{151.21, -33.87} || f.Properties["elems"] != 2.0 {
+				t.Errorf("GeoJSON, expecting 2 elems at [151.21,-33.87], found %v at %v", f.Properties["elems"], c)
+			}
+			kinds["point"]++
+		case "Polygon":
+			var c [][][2]float64
+			json.Unmarshal(f.Geometry.Coordinates, &c)
+			if len(c) != 1 || len(c[0]) != 5 || c[0][0] != c[0][4] {
+				t.Errorf("GeoJSON, expecting a closed ring of 5 positions, found %v", c)
+			}
+			kinds[f.Properties["kind"].(string)]++
+		}
+	}
+	if fc.Type != "FeatureCollection" || kinds["node"] != 1 || kinds["leaf"] != 4 || kinds["point"] != 1 {
+		t.Errorf("GeoJSON, expecting a root node, 4 leaves and 1 point in a FeatureCollection, found %v in a %s", kinds, fc.Type)
+	}
+}
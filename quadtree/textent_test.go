@@ -0,0 +1,138 @@
+package quadtree
+
+import (
+	"errors"
+	"testing"
+)
+
+// Tests that surveying an extent tree finds exactly the extents overlapping the query
+func TestExtentSurvey(t *testing.T) {
+	tree := NewExtentTree[int](0, 1000, 0, 1000)
+	exts := fillExtents(tree, 2000)
+	for i := 0; i < 100; i++ {
+		q := randomExtent(tree.View(), 200)
+		expected := 0
+		for _, ext := range exts {
+			if q.overlaps(ext) {
+				expected++
+			}
+		}
+		found := 0
+		tree.Survey([]*View{q}, func(v *View, e int) {
+			if !v.eq(exts[e]) {
+				t.Errorf("Extent survey, element %d expected at %v found at %v", e, exts[e], v)
+			}
+			found++
+		})
+		if found != expected {
+			t.Errorf("Extent survey %v, expecting %d elements found %d", q, expected, found)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		c := randomCircle(tree.View())
+		expected := 0
+		for _, ext := range exts {
+			if c.overlaps(ext) {
+				expected++
+			}
+		}
+		found := 0
+		tree.SurveyRegion(c, func(_ *View, _ int) {
+			found++
+		})
+		if found != expected {
+			t.Errorf("Extent circle survey %v, expecting %d elements found %d", c, expected, found)
+		}
+	}
+}
+
+// Tests that deleting from an extent tree removes exactly the extents overlapping the region
+func TestExtentDelete(t *testing.T) {
+	tree := NewExtentTree[int](0, 1000, 0, 1000)
+	exts := fillExtents(tree, 2000)
+	remaining := len(exts)
+	for i := 0; i < 10; i++ {
+		q := randomExtent(tree.View(), 200)
+		expected := 0
+		for j, ext := range exts {
+			if ext != nil && q.overlaps(ext) {
+				expected++
+				exts[j] = nil
+			}
+		}
+		deleted := 0
+		tree.Del(q, func(_ *View, _ int) bool {
+			deleted++
+			return true
+		})
+		remaining -= deleted
+		if deleted != expected {
+			t.Errorf("Extent delete %v, expecting %d deleted elements found %d", q, expected, deleted)
+		}
+		if tree.Len() != remaining {
+			t.Errorf("Extent delete %v, expecting %d remaining elements found %d", q, remaining, tree.Len())
+		}
+	}
+	found := 0
+	tree.Survey([]*View{tree.View()}, func(_ *View, e int) {
+		if exts[e] == nil {
+			t.Errorf("Extent delete, found deleted element %d", e)
+		}
+		found++
+	})
+	if found != remaining {
+		t.Errorf("Extent delete, expecting %d elements surveyed found %d", remaining, found)
+	}
+	tree.Del(tree.View(), func(_ *View, _ int) bool { return true })
+	if tree.Len() != 0 || !tree.root.isEmpty() {
+		t.Errorf("Extent delete all, expecting an empty tree found %d elements\n%v", tree.Len(), tree)
+	}
+}
+
+// Tests that an extent tree rejects extents which don't lie entirely within its view
+func TestExtentBounds(t *testing.T) {
+	tree := NewExtentTree[int](0, 1000, 0, 1000)
+	for _, v := range []*View{NewViewP(-1, 10, 0, 10), NewViewP(990, 1001, 0, 10), NewViewP(500, 600, 999, 1002)} {
+		if err := tree.Insert(v, 0); !errors.Is(err, OutOfBoundsErr) {
+			t.Errorf("Extent insert %v, expecting OutOfBoundsErr found %v", v, err)
+		}
+	}
+	if tree.Len() != 0 {
+		t.Errorf("Extent insert out of bounds, expecting an empty tree found %d elements", tree.Len())
+	}
+}
+
+// Tests that many identical extents can be stored without dividing the tree forever
+func TestExtentPileUp(t *testing.T) {
+	tree := NewExtentTree[int](0, 1000, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(PointViewP(123.4, 567.8), i)
+	}
+	found := 0
+	tree.Survey([]*View{PointViewP(123.4, 567.8)}, func(_ *View, _ int) {
+		found++
+	})
+	if found != 1000 {
+		t.Errorf("Extent pile up, expecting 1000 elements found %d", found)
+	}
+}
+
+// Inserts c random extents into tree, each element is the index of its extent in the slice returned
+func fillExtents(tree *ExtentTree[int], c int) []*View {
+	exts := make([]*View, c)
+	for i := range exts {
+		exts[i] = randomExtent(tree.View(), 50)
+		if err := tree.Insert(exts[i], i); err != nil {
+			panic(err)
+		}
+	}
+	return exts
+}
+
+// Returns a random view inside v no wider or taller than maxSize
+func randomExtent(v *View, maxSize float64) *View {
+	x, y := randomPosition(v)
+	rx := clampTo(x+testRand.Float64()*maxSize, v.lx, v.rx)
+	by := clampTo(y+testRand.Float64()*maxSize, v.ty, v.by)
+	return NewViewP(x, rx, y, by)
+}
@@ -0,0 +1,58 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+)
+
+// Tests that each cell of a heatmap counts exactly the elements surveyed within it
+func TestHeatmap(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testHeatmap(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testHeatmap(tree, t)
+	}
+}
+
+func testHeatmap(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 5000)
+	for _, p := range ps {
+		tree.Insert(p.x, p.y, "test")
+	}
+	inf := math.Inf(1)
+	vs := []*View{tree.View(), subView(tree.View()), NewViewP(-inf, inf, -inf, inf), NewViewP(-inf, tree.View().rx, tree.View().ty, inf)}
+	for _, v := range vs {
+		for _, cells := range [][2]int{{1, 1}, {3, 7}, {16, 16}, {256, 256}} {
+			counts := tree.Heatmap(v, cells[0], cells[1])
+			if len(counts) != cells[0] || len(counts[0]) != cells[1] {
+				t.Errorf("Heatmap of %v, expecting %dx%d cells found %dx%d", v, cells[0], cells[1], len(counts), len(counts[0]))
+				continue
+			}
+			expected := make([][]int, cells[0])
+			for i := range expected {
+				expected[i] = make([]int, cells[1])
+			}
+			h := newHeatmap(v, cells[0], cells[1])
+			total := 0
+			tree.Survey([]*View{v}, func(x, y float64, _ interface{}) {
+				xi, yi := h.cell(x, y)
+				expected[xi][yi]++
+				total++
+			})
+			found := 0
+			for i := range counts {
+				for j := range counts[i] {
+					found += counts[i][j]
+					if counts[i][j] != expected[i][j] {
+						t.Errorf("Heatmap of %v with %dx%d cells, expecting %d elements in cell (%d,%d) found %d", v, cells[0], cells[1], expected[i][j], i, j, counts[i][j])
+					}
+				}
+			}
+			if found != total {
+				t.Errorf("Heatmap of %v with %dx%d cells, expecting %d elements in total found %d", v, cells[0], cells[1], total, found)
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns a hash grid over the view of each of testTrees
+// The grids have an odd number of cells, so their cell edges don't line up with the
+// midlines of the views.
+func makeHashGrids() []T {
+	grids := make([]T, len(testTrees))
+	for i, tree := range testTrees {
+		v := tree.View()
+		grids[i] = NewHashGrid[interface{}](v.lx, v.rx, v.ty, v.by, 7, 11)
+	}
+	return grids
+}
+
+// Returns a Z-order index over the view of each of testTrees
+func makeZOrderIndexes() []T {
+	indexes := make([]T, len(testTrees))
+	for i, tree := range testTrees {
+		v := tree.View()
+		indexes[i] = NewZOrderIndex[interface{}](v.lx, v.rx, v.ty, v.by)
+	}
+	return indexes
+}
+
+// Tests that the alternative indexes behave exactly like a plain quadtree
+func TestIndexSequential(t *testing.T) {
+	tests := []func(T, *testing.T){
+		testScatter,
+		testScatterDup,
+		testScatterDelete,
+		testGridAligned,
+		testSurveyUntil,
+		testNearest,
+		testCircleSurvey,
+		testCircleDelete,
+		testPolygonSurvey,
+		testHeatmap,
+		testClusters,
+		testMove,
+	}
+	for _, makeIndexes := range []func() []T{makeHashGrids, makeZOrderIndexes} {
+		for _, test := range tests {
+			for _, index := range makeIndexes() {
+				test(index, t)
+			}
+		}
+	}
+}
+
+// Tests that a snapshot of an alternative index is unaffected by later changes to it
+func TestIndexSnapshot(t *testing.T) {
+	for _, index := range append(makeHashGrids(), makeZOrderIndexes()...) {
+		ps := fillView(index.View(), 1000)
+		for i, p := range ps {
+			index.Insert(p.x, p.y, i)
+		}
+		snap := index.Snapshot()
+		index.Del(index.View(), SimpleDelete())
+		if c := snap.Count([]*View{snap.View()}); c != len(ps) {
+			t.Errorf("Index snapshot, expecting %d elements in the snapshot, counted %d", len(ps), c)
+		}
+		if c := index.Count([]*View{index.View()}); c != 0 {
+			t.Errorf("Index snapshot, expecting no elements in the index, counted %d", c)
+		}
+	}
+}
+
+// Tests that the alternative indexes reject points outside their views
+func TestIndexBounds(t *testing.T) {
+	for _, index := range append(makeHashGrids(), makeZOrderIndexes()...) {
+		v := index.View()
+		if err := index.Insert(v.rx+1, v.by, "out"); err == nil {
+			t.Errorf("Index insert outside %v, expecting an error", v)
+		}
+		if err := index.Insert(math.NaN(), v.ty, "out"); err == nil {
+			t.Errorf("Index insert of NaN in %v, expecting an error", v)
+		}
+		index.Insert(v.lx, v.ty, "in")
+		if err := index.Move(v.lx, v.ty, v.lx-1, v.ty, func(_, _ float64, _ interface{}) bool { return true }); err == nil {
+			t.Errorf("Index move outside %v, expecting an error", v)
+		}
+		if c := index.Count([]*View{PointViewP(v.lx, v.ty)}); c != 1 {
+			t.Errorf("Index move outside %v, expecting the element to stay put, counted %d", v, c)
+		}
+		for _, p := range []point{{math.Inf(1), v.ty}, {v.lx, math.Inf(-1)}, {math.NaN(), v.ty}} {
+			if found := index.Nearest(p.x, p.y, 1, nil); len(found) != 0 {
+				t.Errorf("Index nearest to (%f,%f) in %v, expecting nothing found %v", p.x, p.y, v, found)
+			}
+		}
+	}
+}
+
+// Tests that the nearest elements are found in indexes whose view has no area
+func TestIndexNearestNoArea(t *testing.T) {
+	for _, index := range []T{NewHashGrid[interface{}](5, 5, 5, 5, 1, 1), NewZOrderIndex[interface{}](5, 5, 5, 5)} {
+		index.Insert(5, 5, "in")
+		for _, p := range []point{{5, 5}, {9, 5}, {-1e6, 1e6}} {
+			if found := index.Nearest(p.x, p.y, 2, nil); len(found) != 1 {
+				t.Errorf("Index nearest to (%f,%f) in %v, expecting one element found %v", p.x, p.y, index.View(), found)
+			}
+		}
+	}
+}
+
+// Runs random sequences of inserts, deletes and surveys against a quadtree and each
+// alternative index, checking that they agree after every operation
+func TestIndexModel(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		ops := make([]byte, 100+testRand.Intn(2000))
+		for j := range ops {
+			ops[j] = byte(testRand.Intn(256))
+		}
+		if !testIndexModel(ops, t) {
+			t.Logf("Index model test failed with ops %v", ops)
+			return
+		}
+	}
+}
+
+// Reads operations from ops, encoded as for testModel, applying each to a quadtree and
+// to each alternative index. Returns false if any index disagreed with the quadtree.
+func testIndexModel(ops []byte, t *testing.T) bool {
+	tree := New[int](0, modelSide, 0, modelSide, 10)
+	indexes := []struct {
+		name  string
+		index interface {
+			Insert(x, y float64, e int) error
+			Del(reg Region, pred func(x, y float64, e int) bool)
+			CountRegion(reg Region) int
+			SurveyRegion(reg Region, fun func(x, y float64, e int))
+		}
+	}{
+		{"hash grid", NewHashGrid[int](0, modelSide, 0, modelSide, 5, 9)},
+		{"z-order", NewZOrderIndex[int](0, modelSide, 0, modelSide)},
+	}
+	next := 0
+	arg := func() float64 {
+		if len(ops) == 0 {
+			return 0
+		}
+		b := ops[0]
+		ops = ops[1:]
+		return float64(b)
+	}
+	region := func() *View {
+		lx, rx, ty, by := arg(), arg(), arg(), arg()
+		return NewViewP(math.Min(lx, rx), math.Max(lx, rx), math.Min(ty, by), math.Max(ty, by))
+	}
+	for step := 0; len(ops) > 0; step++ {
+		v := tree.View()
+		switch int(arg()) % 3 {
+		case 0:
+			x, y := arg(), arg()
+			tree.Insert(x, y, next)
+			for _, idx := range indexes {
+				idx.index.Insert(x, y, next)
+			}
+			next++
+		case 1:
+			v = region()
+			divisor := int(arg()) + 1
+			pred := func(_, _ float64, e int) bool {
+				return e%divisor == 0
+			}
+			tree.Del(v, pred)
+			for _, idx := range indexes {
+				idx.index.Del(v, pred)
+			}
+		case 2:
+			v = region()
+		}
+		exp := make(map[int]Point)
+		tree.SurveyRegion(v, func(x, y float64, e int) {
+			exp[e] = Point{x, y}
+		})
+		for _, idx := range indexes {
+			found := make(map[int]Point)
+			idx.index.SurveyRegion(v, func(x, y float64, e int) {
+				if _, ok := found[e]; ok {
+					t.Errorf("Step %d, %s survey of %v found element %d more than once", step, idx.name, v, e)
+				}
+				found[e] = Point{x, y}
+			})
+			if len(found) != len(exp) {
+				t.Errorf("Step %d, %s survey of %v found %d elements, expecting %d", step, idx.name, v, len(found), len(exp))
+				return false
+			}
+			for e, p := range exp {
+				if found[e] != p {
+					t.Errorf("Step %d, %s survey of %v found element %d at %v, expecting %v", step, idx.name, v, e, found[e], p)
+					return false
+				}
+			}
+			if c := idx.index.CountRegion(v); c != len(exp) {
+				t.Errorf("Step %d, %s count of %v is %d, expecting %d", step, idx.name, v, c, len(exp))
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Tests that Z-order keys interleave the bits of x and y, with x taking the lower bit
+func TestZKey(t *testing.T) {
+	for _, k := range []struct {
+		qx, qy, key uint64
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{0, 1, 2},
+		{3, 3, 15},
+		{4, 0, 16},
+		{0xFFFF, 0, 0x55555555},
+		{zQuanta - 1, zQuanta - 1, math.MaxUint64},
+	} {
+		if key := zKey(k.qx, k.qy); key != k.key {
+			t.Errorf("Z-order key of (%d,%d), expecting %x found %x", k.qx, k.qy, k.key, key)
+		}
+	}
+}
+
+// Tests that every point is quantised into a quantum whose edges contain it,
+// including points on the edges of the view
+func TestZQuantum(t *testing.T) {
+	for _, axis := range [][2]float64{{0, 1}, {-20.4, 20.4}, {0, 1e10}, {-500.00000001, 500.00000001}} {
+		lo, hi := axis[0], axis[1]
+		cs := []float64{lo, hi, lo + (hi-lo)/2, math.Nextafter(hi, lo), math.Nextafter(lo, hi)}
+		for i := 0; i < 1000; i++ {
+			cs = append(cs, lo+testRand.Float64()*(hi-lo))
+		}
+		for _, c := range cs {
+			q := zQuantum(lo, hi, c)
+			if q >= zQuanta || c < zEdge(lo, hi, q) || c > zEdge(lo, hi, q+1) {
+				t.Errorf("Z-order quantum of %v along [%v,%v] is %d, whose edges are %v and %v", c, lo, hi, q, zEdge(lo, hi, q), zEdge(lo, hi, q+1))
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package quadtree
+
+import (
+	"errors"
+	"testing"
+)
+
+// Returns a Keyed index, keying each element by itself, wrapping a quadtree, a hash grid
+// and a Z-order index over the view of each of testTrees
+func makeKeyedIndexes() []T {
+	var indexes []T
+	for _, tree := range testTrees {
+		v := tree.View()
+		for _, index := range []T{
+			NewQuadTree(v.lx, v.rx, v.ty, v.by, treeLim),
+			NewHashGrid[interface{}](v.lx, v.rx, v.ty, v.by, 7, 11),
+			NewZOrderIndex[interface{}](v.lx, v.rx, v.ty, v.by),
+		} {
+			indexes = append(indexes, NewKeyed(Index[interface{}](index), func(e interface{}) interface{} { return e }))
+		}
+	}
+	return indexes
+}
+
+// Tests that a Keyed index surveys, deletes and moves elements just like the index it wraps
+func TestKeyedSequential(t *testing.T) {
+	for _, test := range []func(T, *testing.T){testScatterDelete, testSurveyUntil, testMove} {
+		for _, index := range makeKeyedIndexes() {
+			test(index, t)
+		}
+	}
+}
+
+// Tests that elements can be found, moved and removed by their key alone
+func TestKeyed(t *testing.T) {
+	for _, index := range []Index[int]{
+		New[int](0, 100, 0, 100, treeLim),
+		NewHashGrid[int](0, 100, 0, 100, 5, 9),
+		NewZOrderIndex[int](0, 100, 0, 100),
+	} {
+		kd := NewKeyed(index, func(e int) int { return e })
+		ps := fillView(kd.View(), 1000)
+		for i, p := range ps {
+			kd.Insert(p.x, p.y, i)
+		}
+		for i := 0; i < len(ps); i += 2 {
+			x, y := randomPosition(kd.View())
+			if err := kd.MoveTo(i, x, y); err != nil {
+				t.Errorf("Keyed, moving %d to (%f,%f) failed %v", i, x, y, err)
+			}
+			ps[i] = point{x, y}
+		}
+		for i := 0; i < len(ps); i += 3 {
+			if !kd.Remove(i) {
+				t.Errorf("Keyed, expecting to remove %d", i)
+			}
+			if kd.Remove(i) {
+				t.Errorf("Keyed, expecting %d to be removed only once", i)
+			}
+		}
+		for i, p := range ps {
+			loc, e, ok := kd.Get(i)
+			if i%3 == 0 {
+				if ok {
+					t.Errorf("Keyed, expecting %d to have been removed, found it at %v", i, loc)
+				}
+				continue
+			}
+			if !ok || e != i || loc != (Point{p.x, p.y}) {
+				t.Errorf("Keyed, expecting %d at (%f,%f), found %d at %v (%v)", i, p.x, p.y, e, loc, ok)
+			}
+		}
+		if c := kd.Count([]*View{kd.View()}); c != kd.Len() || c != len(ps)-(len(ps)+2)/3 {
+			t.Errorf("Keyed, counted %d elements, with %d keys, expecting %d", c, kd.Len(), len(ps)-(len(ps)+2)/3)
+		}
+		if err := kd.MoveTo(0, 50, 50); !errors.Is(err, UnknownKeyErr) {
+			t.Errorf("Keyed, expecting moving a removed key to fail, found %v", err)
+		}
+	}
+}
+
+// An index whose Move never finds the elements it is asked to move
+type stuckIndex[E any] struct {
+	Index[E]
+}
+
+func (s stuckIndex[E]) Move(_, _, _, _ float64, _ func(x, y float64, e E) bool) error {
+	return nil
+}
+
+// Tests that a key's location is kept when moving it moves nothing
+func TestKeyedMoveMissing(t *testing.T) {
+	kd := NewKeyed(Index[int](stuckIndex[int]{New[int](0, 100, 0, 100, treeLim)}), func(e int) int { return e })
+	kd.Insert(50, 10, 1)
+	if err := kd.MoveTo(1, 60, 10); !errors.Is(err, UnknownKeyErr) {
+		t.Errorf("Keyed move missing, expecting an unknown key error, found %v", err)
+	}
+	if p, e, ok := kd.Get(1); !ok || e != 1 || p != (Point{50, 10}) {
+		t.Errorf("Keyed move missing, expecting 1 at (50,10), found %d at %v (%v)", e, p, ok)
+	}
+}
+
+// Tests that moving a key stored more than once moves every element with that key,
+// reporting an error, and leaves the key located with its elements
+func TestKeyedMoveDuplicates(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	kd := NewKeyed(Index[int](tree), func(e int) int { return e / 10 })
+	kd.Insert(50, 10, 11)
+	tree.Insert(50, 10, 12)
+	if err := kd.MoveTo(1, 60, 10); err == nil {
+		t.Errorf("Keyed move duplicates, expecting an error moving two elements keyed 1")
+	}
+	if p, e, ok := kd.Get(1); !ok || e/10 != 1 || p != (Point{60, 10}) {
+		t.Errorf("Keyed move duplicates, expecting 1 at (60,10), found %d at %v (%v)", e, p, ok)
+	}
+	if c := kd.Count([]*View{PointViewP(60, 10)}); c != 2 {
+		t.Errorf("Keyed move duplicates, expecting both elements at (60,10), counted %d", c)
+	}
+	if !kd.Remove(1) || kd.Count([]*View{kd.View()}) != 0 {
+		t.Errorf("Keyed move duplicates, expecting removing 1 to remove both elements")
+	}
+}
+
+// Tests that inserting an element whose key is already stored replaces the stored element
+// unless the new location lies outside the index
+func TestKeyedReplace(t *testing.T) {
+	type named struct {
+		name string
+		age  int
+	}
+	kd := NewKeyed(Index[named](New[named](0, 10, 0, 10, treeLim)), func(n named) string { return n.name })
+	kd.Insert(1, 1, named{"a", 1})
+	kd.Insert(1, 1, named{"a", 2})
+	kd.Insert(5, 5, named{"a", 3})
+	if c := kd.Count([]*View{kd.View()}); c != 1 || kd.Len() != 1 {
+		t.Errorf("Keyed replace, expecting a single element, counted %d with %d keys", c, kd.Len())
+	}
+	if err := kd.Insert(20, 5, named{"a", 4}); !errors.Is(err, OutOfBoundsErr) {
+		t.Errorf("Keyed replace, expecting an error inserting outside %v, found %v", kd.View(), err)
+	}
+	if p, n, ok := kd.Get("a"); !ok || n.age != 3 || p != (Point{5, 5}) {
+		t.Errorf("Keyed replace, expecting the third element at (5,5), found %v at %v (%v)", n, p, ok)
+	}
+	kd.Del(kd.View(), func(_, _ float64, n named) bool { return n.name == "a" })
+	if _, _, ok := kd.Get("a"); ok || kd.Len() != 0 {
+		t.Errorf("Keyed replace, expecting del to forget the key, found %d keys", kd.Len())
+	}
+}
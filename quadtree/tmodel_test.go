@@ -0,0 +1,258 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// The side of the square view used by model tests. Coordinates are whole numbers
+// from 0 to 255, so many points lie exactly on the borders between subtrees.
+const modelSide = 256
+
+// Fuzzes sequences of inserts, deletes and surveys, checking the tree against a brute-force model
+func FuzzQuadTree(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 0, 128, 128, 2, 0, 0, 255, 255})
+	f.Add([]byte{0, 128, 64, 0, 128, 64, 0, 64, 128, 1, 0, 255, 0, 255, 1, 2, 0, 0, 128, 128})
+	seed := make([]byte, 0, 300)
+	for i := 0; i < 100; i++ {
+		seed = append(seed, 0, byte(i*16%256), byte(i/16*16))
+	}
+	f.Add(append(seed, 1, 0, 255, 0, 255, 3, 2, 0, 0, 255, 255))
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		testModel(ops, DefaultMaxDepth, MidpointSplit, t)
+		testModel(ops, 2, MidpointSplit, t)
+		testModel(ops, DefaultMaxDepth, MedianSplit, t)
+		testModel(ops, 2, MedianSplit, t)
+	})
+}
+
+// Runs random sequences of inserts, deletes and surveys, checking the tree against a brute-force model
+// Every other sequence is run on a tree limited to a depth of 2, so its leaves overflow,
+// and every other pair of sequences on a tree dividing its leaves at the median.
+func TestModel(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		ops := make([]byte, 100+testRand.Intn(2000))
+		for j := range ops {
+			ops[j] = byte(testRand.Intn(256))
+		}
+		maxDepth := DefaultMaxDepth
+		if i%2 == 1 {
+			maxDepth = 2
+		}
+		split := MidpointSplit
+		if i%4 >= 2 {
+			split = MedianSplit
+		}
+		if !testModel(ops, maxDepth, split, t) {
+			t.Logf("Model test failed with ops %v", ops)
+			return
+		}
+	}
+}
+
+// A brute-force model of a quadtree, mapping each element to its location
+type model map[int]point
+
+// Reads operations from ops, applying each to both a quadtree and a model, and checks
+// that they agree after every operation. Each operation is an opcode byte followed by its arguments
+//
+//	insert: opcode%3 == 0, x, y
+//	delete: opcode%3 == 1, lx, rx, ty, by, divisor, deleting elements divisible by divisor+1
+//	survey: opcode%3 == 2, lx, rx, ty, by
+//
+// A small leaf allocation is used so that the tree runs through its static allocation.
+// The tree's leaves are divided no deeper than maxDepth, using split.
+// Returns false if any check failed.
+func testModel(ops []byte, maxDepth int, split SplitStrategy, t *testing.T) bool {
+	tree := New[int](0, modelSide, 0, modelSide, 10)
+	tree.SetDepthLimits(maxDepth, 0)
+	tree.SetSplitStrategy(split)
+	m := make(model)
+	next := 0
+	arg := func() float64 {
+		if len(ops) == 0 {
+			return 0
+		}
+		b := ops[0]
+		ops = ops[1:]
+		return float64(b)
+	}
+	region := func() *View {
+		lx, rx, ty, by := arg(), arg(), arg(), arg()
+		if rx < lx {
+			lx, rx = rx, lx
+		}
+		if by < ty {
+			ty, by = by, ty
+		}
+		return NewViewP(lx, rx, ty, by)
+	}
+	for step := 0; len(ops) > 0; step++ {
+		op := int(arg())
+		switch op % 3 {
+		case 0:
+			x, y := arg(), arg()
+			tree.Insert(x, y, next)
+			m[next] = point{x, y}
+			next++
+		case 1:
+			v := region()
+			divisor := int(arg()) + 1
+			pred := func(_, _ float64, e int) bool {
+				return e%divisor == 0
+			}
+			tree.Del(v, pred)
+			for e, p := range m {
+				if v.contains(p.x, p.y) && pred(p.x, p.y, e) {
+					delete(m, e)
+				}
+			}
+		case 2:
+			if !checkModelSurvey(tree, m, region(), step, t) {
+				return false
+			}
+		}
+		if !checkModelSurvey(tree, m, tree.View(), step, t) || !checkInvariants(tree, step, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks that surveying v finds exactly the elements of m lying within v, each once and at its own location
+// Returns false if the check failed.
+func checkModelSurvey(tree *QuadTree[int], m model, v *View, step int, t *testing.T) bool {
+	found := make(map[int]bool)
+	ok := true
+	tree.Survey([]*View{v}, func(x, y float64, e int) {
+		p, in := m[e]
+		if !in || p != (point{x, y}) {
+			t.Errorf("Step %d, survey of %v found unexpected element %d at (%f,%f)", step, v, e, x, y)
+			ok = false
+		}
+		if found[e] {
+			t.Errorf("Step %d, survey of %v found element %d at (%f,%f) more than once", step, v, e, x, y)
+			ok = false
+		}
+		found[e] = true
+	})
+	for e, p := range m {
+		if v.contains(p.x, p.y) && !found[e] {
+			t.Errorf("Step %d, survey of %v lost element %d at (%f,%f)", step, v, e, p.x, p.y)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Checks the structural invariants of tree
+//
+//	each leaf's non-empty vpoints precede its empty ones, as restoreOrder maintains
+//	each point lies within the view of its leaf
+//	only leaves which can't be divided overflow, and no leaf holds fewer than LEAF_SIZE vpoints
+//	each node's children are the quarters of its view, or once any leaf has been divided at
+//	the median divide it at some point strictly inside it, and its count is the sum of theirs
+//	the free lists hold no cycles and no subtree still in use, and together with the
+//	subtrees in use account for every statically allocated node and leaf
+//
+// Returns false if any check failed.
+func checkInvariants(tree *QuadTree[int], step int, t *testing.T) bool {
+	ok := true
+	fail := func(format string, args ...interface{}) {
+		t.Errorf("Step %d, "+format, append([]interface{}{step}, args...)...)
+		ok = false
+	}
+	freeNodes := make(map[*node[int]]bool)
+	for n := tree.freeNode; n != nil; n = n.nextFree {
+		if freeNodes[n] {
+			fail("free node list has a cycle")
+			return false
+		}
+		freeNodes[n] = true
+	}
+	freeLeaves := make(map[*leaf[int]]bool)
+	for l := tree.freeLeaf; l != nil; l = l.nextFree {
+		if freeLeaves[l] {
+			fail("free leaf list has a cycle")
+			return false
+		}
+		freeLeaves[l] = true
+	}
+	if len(freeNodes) != tree.freeNodes() || len(freeLeaves) != tree.freeLeaves() {
+		fail("free lists hold %d nodes and %d leaves, freeNodes and freeLeaves count %d and %d", len(freeNodes), len(freeLeaves), tree.freeNodes(), tree.freeLeaves())
+	}
+	staticNodes, staticLeaves := 0, 0
+	var walk func(st subtree[int]) int
+	walk = func(st subtree[int]) int {
+		switch st.(type) {
+		case *leaf[int]:
+			l := st.(*leaf[int])
+			if freeLeaves[l] {
+				fail("leaf %v is in use and free", &l.view)
+			}
+			if !l.disposable {
+				staticLeaves++
+			}
+			if len(l.ps) < LEAF_SIZE || (len(l.ps) > LEAF_SIZE && tree.canDivide(&l.view)) {
+				fail("leaf %v at depth %d holds %d vpoints", &l.view, tree.depthOf(&l.view), len(l.ps))
+			}
+			count := 0
+			for i := range l.ps {
+				p := &l.ps[i]
+				if p.zeroed() {
+					for j := i + 1; j < len(l.ps); j++ {
+						if !l.ps[j].zeroed() {
+							fail("leaf %v has a vpoint at %d after an empty vpoint at %d", &l.view, j, i)
+						}
+					}
+					break
+				}
+				if len(p.elems) == 0 {
+					fail("leaf %v has a non-empty vpoint with no elements", &l.view)
+				}
+				if !l.view.contains(p.x, p.y) {
+					fail("leaf %v holds (%f,%f)", &l.view, p.x, p.y)
+				}
+				count += len(p.elems)
+			}
+			return count
+		case *node[int]:
+			n := st.(*node[int])
+			if freeNodes[n] {
+				fail("node %v is in use and free", &n.view)
+			}
+			if !n.disposable {
+				staticNodes++
+			}
+			v1, v2, v3, v4 := n.view.quarters()
+			if tree.uneven && n.children[0] != nil {
+				// Each node is divided at the bottom right corner of its first child
+				x, y := n.children[0].View().rx, n.children[0].View().by
+				if !(n.view.lx < x && x < n.view.rx && n.view.ty < y && y < n.view.by) {
+					fail("node %v is divided at (%f,%f) outside it", &n.view, x, y)
+				}
+				v1, v2, v3, v4 = n.view.divide(x, y)
+			}
+			count := 0
+			for i, q := range []*View{v1, v2, v3, v4} {
+				if n.children[i] == nil || !n.children[i].View().eq(q) {
+					fail("node %v has child %d which is not its quarter %v", &n.view, i, q)
+					continue
+				}
+				count += walk(n.children[i])
+			}
+			if count != n.agg.Count {
+				fail("node %v counts %d elements, its children hold %d", &n.view, n.agg.Count, count)
+			}
+			return count
+		}
+		return 0
+	}
+	walk(tree.rootNode)
+	if staticNodes+len(freeNodes) != len(tree.nodes) {
+		fail("%d nodes in use and %d free, expecting %d in total", staticNodes, len(freeNodes), len(tree.nodes))
+	}
+	if staticLeaves+len(freeLeaves) != len(tree.leaves) {
+		fail("%d leaves in use and %d free, expecting %d in total", staticLeaves, len(freeLeaves), len(tree.leaves))
+	}
+	return ok
+}
@@ -0,0 +1,124 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// Tests that moved elements are found at their new location and not at their old location
+func TestMove(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testMove(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testMove(tree, t)
+	}
+}
+
+func testMove(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for i := 0; i < len(ps); i += 2 {
+		var x, y float64
+		if i%4 == 0 {
+			// Move a long way, probably into another leaf
+			x, y = randomPosition(tree.View())
+		} else {
+			// Move a short way, probably within the same leaf
+			x, y = randomPosition(NewViewP(ps[i].x, ps[i].x+tree.View().width()/1e4, ps[i].y, ps[i].y+tree.View().height()/1e4))
+			x = clampTo(x, tree.View().lx, tree.View().rx)
+			y = clampTo(y, tree.View().ty, tree.View().by)
+		}
+		moving := i
+		tree.Move(ps[i].x, ps[i].y, x, y, func(_, _ float64, e interface{}) bool {
+			return e == moving
+		})
+		ps[i] = point{x, y}
+	}
+	for i, p := range ps {
+		found := 0
+		tree.Survey([]*View{PointViewP(p.x, p.y)}, func(_, _ float64, e interface{}) {
+			if e == i {
+				found++
+			}
+		})
+		if found != 1 {
+			t.Errorf("Move, expecting element %d once at (%f,%f), found %d times", i, p.x, p.y, found)
+		}
+	}
+	testSurveyScatter(tree, ps, 1, "Move", t)
+}
+
+// Tests that moving an element within a single leaf does not restructure the tree
+func TestMoveInPlace(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	for i := 0; i < LEAF_SIZE; i++ {
+		tree.Insert(float64(i), float64(i), i)
+	}
+	freeNodes, freeLeaves := tree.freeNodes(), tree.freeLeaves()
+	for i := 0; i < LEAF_SIZE; i++ {
+		tree.Move(float64(i), float64(i), float64(i)+0.5, float64(i)+0.5, func(_, _ float64, e int) bool {
+			return true
+		})
+	}
+	if tree.freeNodes() != freeNodes || tree.freeLeaves() != freeLeaves {
+		t.Errorf("Move in place, expecting %d free nodes and %d free leaves, found %d and %d", freeNodes, freeLeaves, tree.freeNodes(), tree.freeLeaves())
+	}
+	count := 0
+	tree.Survey([]*View{NewViewP(0.5, 50, 0.5, 50)}, func(x, y float64, e int) {
+		if x != float64(e)+0.5 || y != float64(e)+0.5 {
+			t.Errorf("Move in place, found element %d at (%f,%f)", e, x, y)
+		}
+		count++
+	})
+	if count != LEAF_SIZE {
+		t.Errorf("Move in place, expecting %d elements found %d", LEAF_SIZE, count)
+	}
+}
+
+// Tests moving elements lying on the borders between subtrees, which are stored in the
+// first subtree containing them, into later subtrees which also contain them
+func TestMoveBorder(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	ps := []point{{50, 10}, {50, 50}, {25, 50}, {50, 75}}
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(float64(i), 100-float64(i)/2, len(ps)+i)
+	}
+	moves := []point{{60, 10}, {60, 60}, {25, 60}, {60, 75}}
+	for i, p := range ps {
+		moving := i
+		tree.Move(p.x, p.y, moves[i].x, moves[i].y, func(_, _ float64, e int) bool {
+			return e == moving
+		})
+	}
+	for i, p := range moves {
+		found := 0
+		tree.Survey([]*View{tree.View()}, func(x, y float64, e int) {
+			if e == i {
+				found++
+				if x != p.x || y != p.y {
+					t.Errorf("Move border, expecting element %d at (%f,%f) found it at (%f,%f)", i, p.x, p.y, x, y)
+				}
+			}
+		})
+		if found != 1 {
+			t.Errorf("Move border, expecting element %d once found %d times", i, found)
+		}
+	}
+	checkInvariants(tree, 0, t)
+}
+
+func clampTo(f, min, max float64) float64 {
+	if f < min {
+		return min
+	}
+	if f > max {
+		return max
+	}
+	return f
+}
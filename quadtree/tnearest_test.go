@@ -0,0 +1,67 @@
+package quadtree
+
+import (
+	"sort"
+	"testing"
+)
+
+// Tests that Nearest returns the same distances, in the same order, as a brute force search
+func TestNearest(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testNearest(tree, t)
+	}
+}
+
+func testNearest(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for _, k := range []int{0, 1, 5, 16, 100, 2000} {
+		x, y := randomPosition(tree.View())
+		expDists := make([]float64, len(ps))
+		for i, p := range ps {
+			expDists[i] = distSq(x, y, p.x, p.y)
+		}
+		sort.Float64s(expDists)
+		if k < len(expDists) {
+			expDists = expDists[:k]
+		}
+		found := tree.Nearest(x, y, k, nil)
+		if len(found) != len(expDists) {
+			t.Errorf("Nearest %d to (%f,%f), expecting %d elements found %d", k, x, y, len(expDists), len(found))
+			continue
+		}
+		for i, e := range found {
+			p := ps[e.(int)]
+			if d := distSq(x, y, p.x, p.y); d != expDists[i] {
+				t.Errorf("Nearest %d to (%f,%f), element %d at distance %f expecting %f", k, x, y, i, d, expDists[i])
+			}
+		}
+	}
+}
+
+// Tests that elements rejected by the filter are never returned by Nearest
+func TestNearestFilter(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		ps := fillView(tree.View(), 1000)
+		for i, p := range ps {
+			tree.Insert(p.x, p.y, i)
+		}
+		even := func(x, y float64, e interface{}) bool {
+			return e.(int)%2 == 0
+		}
+		x, y := randomPosition(tree.View())
+		found := tree.Nearest(x, y, 10, even)
+		if len(found) != 10 {
+			t.Errorf("Filtered nearest expecting 10 elements, found %d", len(found))
+		}
+		for _, e := range found {
+			if e.(int)%2 != 0 {
+				t.Errorf("Filtered nearest returned rejected element %v", e)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// Tests that surveying with a polygon finds exactly the points inside it, and outside its hole
+func TestPolygonSurvey(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testPolygonSurvey(tree, t)
+	}
+}
+
+func testPolygonSurvey(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for _, p := range ps {
+		tree.Insert(p.x, p.y, "test")
+	}
+	pg := testPolygon(tree.View())
+	var count int
+	for _, p := range ps {
+		if pg.contains(p.x, p.y) {
+			count++
+		}
+	}
+	fun, results := SimpleSurvey()
+	tree.SurveyRegion(pg, fun)
+	if count != results.Len() {
+		t.Errorf("Polygon survey, expecting %d elements found %d", count, results.Len())
+	}
+	pred, deleted := CollectingDelete()
+	tree.Del(pg, pred)
+	if count != deleted.Len() {
+		t.Errorf("Polygon delete, expecting %d deleted elements found %d", count, deleted.Len())
+	}
+}
+
+// Tests that a polygon overlaps every view which contains a point inside the polygon
+func TestPolygonOverlaps(t *testing.T) {
+	v := OrigViewP(100, 100)
+	pg := testPolygon(v)
+	ps := fillView(v, 10000)
+	for i := 0; i < 1000; i++ {
+		sv := subView(v)
+		for _, p := range ps {
+			if sv.contains(p.x, p.y) && pg.contains(p.x, p.y) {
+				if !pg.overlaps(sv) {
+					t.Errorf("Polygon contains (%f,%f) in %v but does not overlap it", p.x, p.y, sv)
+				}
+				break
+			}
+		}
+	}
+	hole := NewViewP(45, 55, 45, 55)
+	if pg.overlaps(hole) {
+		t.Errorf("Polygon overlaps %v which lies entirely inside its hole", hole)
+	}
+	outside := NewViewP(0, 5, 0, 5)
+	if pg.overlaps(outside) {
+		t.Errorf("Polygon overlaps %v which lies entirely outside it", outside)
+	}
+}
+
+// Tests that polygons with fewer than three vertices are illegal
+func TestIllegalPolygon(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Test did not panic, polygon with two vertices should be illegal")
+		}
+	}()
+	NewPolygon([]Point{{0, 0}, {1, 1}})
+}
+
+// Returns a concave, arrow shaped, polygon with a square hole in its centre
+// scaled to fit inside v
+func testPolygon(v *View) *Polygon {
+	scale := func(ps []Point) []Point {
+		scaled := make([]Point, len(ps))
+		for i, p := range ps {
+			scaled[i] = Point{v.lx + p.X*v.width()/100, v.ty + p.Y*v.height()/100}
+		}
+		return scaled
+	}
+	outer := []Point{{10, 10}, {50, 30}, {90, 10}, {70, 50}, {90, 90}, {50, 70}, {10, 90}, {30, 50}}
+	hole := []Point{{40, 40}, {60, 40}, {60, 60}, {40, 60}}
+	return NewPolygon(scale(outer), scale(hole))
+}
@@ -42,7 +42,7 @@ func init() {
 
 func clearTrees() {
 	for i := range testTrees {
-		testTrees[i].Delete(testTrees[i].View(), SimpleDelete())
+		testTrees[i].Del(testTrees[i].View(), SimpleDelete())
 	}
 }
 
@@ -153,6 +153,104 @@ func testScatterDup(tree T, t *testing.T) {
 	}
 }
 
+// Tests that elements lying on the borders between subtrees are stored once
+// Our simulators place users on regular grids, so many points fall exactly on the
+// midlines of the tree's views. Each must be surveyed, counted and deleted exactly once.
+func TestGridAligned(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testGridAligned(tree, t)
+	}
+	clearTrees()
+}
+
+func testGridAligned(tree T, t *testing.T) {
+	v := tree.View()
+	ps := gridView(v, 16)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for i := 0; i < 100; i++ {
+		sv := v
+		if i > 0 {
+			sv = subView(v)
+		}
+		count := 0
+		for _, p := range ps {
+			if sv.contains(p.x, p.y) {
+				count++
+			}
+		}
+		found := make(map[interface{}]int)
+		tree.Survey([]*View{sv}, func(x, y float64, e interface{}) {
+			found[e]++
+		})
+		for e, c := range found {
+			if c != 1 {
+				t.Errorf("Grid aligned survey of %v found element %v %d times in %v", sv, e, c, v)
+			}
+		}
+		if len(found) != count {
+			t.Errorf("Grid aligned survey of %v expected %d elements, found %d in %v", sv, count, len(found), v)
+		}
+		if c := tree.Count([]*View{sv}); c != count {
+			t.Errorf("Grid aligned count of %v expected %d elements, counted %d in %v", sv, count, c, v)
+		}
+	}
+	for i, p := range ps {
+		deleted := 0
+		tree.Del(NewViewP(p.x, p.x, p.y, p.y), func(x, y float64, e interface{}) bool {
+			if e == i {
+				deleted++
+				return true
+			}
+			return false
+		})
+		if deleted != 1 {
+			t.Errorf("Grid aligned delete of element %d at (%f,%f) deleted %d in %v", i, p.x, p.y, deleted, v)
+		}
+	}
+	if c := tree.Count([]*View{v}); c != 0 {
+		t.Errorf("Grid aligned deletes left %d elements in %v", c, v)
+	}
+}
+
+// Tests that many elements inserted at the centre of a tree, where all four quarters
+// meet, are all stored in a single leaf
+func TestCentreAligned(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	for i := 0; i < 100; i++ {
+		tree.Insert(50, 50, i)
+	}
+	leaves := 0
+	var walk func(st subtree[int])
+	walk = func(st subtree[int]) {
+		switch st.(type) {
+		case *leaf[int]:
+			l := st.(*leaf[int])
+			if !l.ps[0].zeroed() {
+				leaves++
+			}
+		case *node[int]:
+			n := st.(*node[int])
+			for i := range n.children {
+				walk(n.children[i])
+			}
+		}
+	}
+	walk(tree.rootNode)
+	if leaves != 1 {
+		t.Errorf("Expected elements at the centre to be stored in 1 leaf, found %d", leaves)
+	}
+	count := 0
+	tree.Survey([]*View{tree.View()}, func(x, y float64, e int) {
+		count++
+	})
+	if count != 100 {
+		t.Errorf("Expected to survey 100 elements at the centre, found %d", count)
+	}
+}
+
 // Tests that when we
 // 1: Add a single element to an empty tree
 // 2: Remove that element from the tree
@@ -326,7 +424,7 @@ func disabledTestInsertLimits(t *testing.T) {
 */
 
 func testDelete(tree T, view *View, pred func(x, y float64, e interface{}) bool, deleted, expDel *list.List, t *testing.T, errPfx string) {
-	tree.Delete(view, pred)
+	tree.Del(view, pred)
 	if deleted.Len() != expDel.Len() {
 		t.Errorf("%s: Expecting %v deleted element(s), found %v", errPfx, expDel.Len(), deleted.Len())
 	}
@@ -395,6 +493,20 @@ func fillView(v *View, c int) []point {
 	return ps
 }
 
+// Returns the points of a grid with cells+1 points along each side of v, including its borders
+// When cells is a power of two every midline of the tree's views passes through the grid.
+func gridView(v *View, cells int) []point {
+	ps := make([]point, 0, (cells+1)*(cells+1))
+	for i := 0; i <= cells; i++ {
+		for j := 0; j <= cells; j++ {
+			x := v.lx + (v.rx-v.lx)*float64(i)/float64(cells)
+			y := v.ty + (v.by-v.ty)*float64(j)/float64(cells)
+			ps = append(ps, point{x: x, y: y})
+		}
+	}
+	return ps
+}
+
 func subView(v *View) *View {
 	lx := testRand.Float64()*(v.rx-v.lx) + v.lx
 	rx := testRand.Float64()*(v.rx-lx) + lx
@@ -402,3 +514,105 @@ func subView(v *View) *View {
 	by := testRand.Float64()*(v.by-ty) + ty
 	return NewViewP(lx, rx, ty, by)
 }
+
+// Tests that a typed quadtree stores, surveys, finds and deletes elements of its own type
+func TestTypedQuadTree(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	ps := fillView(tree.View(), 1000)
+	expSum := 0
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+		expSum += i
+	}
+	sum := 0
+	tree.Survey([]*View{tree.View()}, func(x, y float64, e int) {
+		sum += e
+	})
+	if sum != expSum {
+		t.Errorf("Typed survey, expecting sum of elements %d found %d", expSum, sum)
+	}
+	for _, e := range tree.Nearest(50, 50, 10, nil) {
+		if p := ps[e]; distSq(50, 50, p.x, p.y) > 50*50*2 {
+			t.Errorf("Typed nearest returned element %d at (%f,%f) outside the tree", e, p.x, p.y)
+		}
+	}
+	deleted := 0
+	tree.Del(tree.View(), func(x, y float64, e int) bool {
+		deleted++
+		return e%2 == 0
+	})
+	if deleted != len(ps) {
+		t.Errorf("Typed delete, expecting %d elements tested found %d", len(ps), deleted)
+	}
+	tree.Survey([]*View{tree.View()}, func(x, y float64, e int) {
+		if e%2 == 0 {
+			t.Errorf("Typed delete, found deleted element %d", e)
+		}
+	})
+}
+
+// Tests that SurveyUntil stops as soon as its function returns false,
+// and that Within yields every element and can be broken out of
+func TestSurveyUntil(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testSurveyUntil(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testSurveyUntil(tree, t)
+	}
+}
+
+func testSurveyUntil(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	for _, limit := range []int{1, 10, 999, 1000} {
+		count := 0
+		tree.SurveyUntil(tree.View(), func(x, y float64, e interface{}) bool {
+			count++
+			return count < limit
+		})
+		if count != limit {
+			t.Errorf("Survey until, expecting to stop after %d elements, stopped after %d", limit, count)
+		}
+	}
+	count := 0
+	for p, e := range tree.Within(tree.View()) {
+		if exp := ps[e.(int)]; p.X != exp.x || p.Y != exp.y {
+			t.Errorf("Within, expecting element %v at (%f,%f) found (%f,%f)", e, exp.x, exp.y, p.X, p.Y)
+		}
+		count++
+	}
+	if count != len(ps) {
+		t.Errorf("Within, expecting %d elements found %d", len(ps), count)
+	}
+	count = 0
+	for range tree.Within(tree.View()) {
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	if count != 10 {
+		t.Errorf("Within, expecting to break after 10 elements, found %d", count)
+	}
+}
+
+// Tests that the static leaves of a node taken from the heap, once every static node is
+// in use, are recycled when that node is collapsed
+func TestRecycleDisposableNode(t *testing.T) {
+	tree := New[int](0, 256, 0, 256, 10)
+	leaves := tree.freeLeaves()
+	freeNode := tree.freeNode
+	tree.freeNode = nil
+	for i := 0; i <= LEAF_SIZE; i++ {
+		tree.Insert(float64(i), float64(i), i)
+	}
+	tree.Del(tree.View(), func(_, _ float64, _ int) bool { return true })
+	tree.freeNode = freeNode
+	if tree.freeLeaves() < leaves {
+		t.Errorf("Recycle disposable node, expecting at least %d free leaves found %d", leaves, tree.freeLeaves())
+	}
+}
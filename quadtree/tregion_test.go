@@ -0,0 +1,192 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+)
+
+// Tests that surveying with a circle finds exactly the points within its radius
+func TestCircleSurvey(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testCircleSurvey(tree, t)
+	}
+}
+
+func testCircleSurvey(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for _, p := range ps {
+		tree.Insert(p.x, p.y, "test")
+	}
+	for i := 0; i < 100; i++ {
+		c := randomCircle(tree.View())
+		var count int
+		for _, p := range ps {
+			if math.Sqrt(distSq(c.x, c.y, p.x, p.y)) <= c.radius {
+				count++
+			}
+		}
+		fun, results := SimpleSurvey()
+		tree.SurveyRegion(c, fun)
+		if count != results.Len() {
+			t.Errorf("Circle survey %v, expecting %d elements found %d", c, count, results.Len())
+		}
+	}
+}
+
+// Tests that deleting with a circle removes exactly the points within its radius
+func TestCircleDelete(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testCircleDelete(tree, t)
+	}
+}
+
+func testCircleDelete(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for _, p := range ps {
+		tree.Insert(p.x, p.y, "test")
+	}
+	c := randomCircle(tree.View())
+	var count int
+	for _, p := range ps {
+		if c.contains(p.x, p.y) {
+			count++
+		}
+	}
+	pred, deleted := CollectingDelete()
+	tree.Del(c, pred)
+	if count != deleted.Len() {
+		t.Errorf("Circle delete %v, expecting %d deleted elements found %d", c, count, deleted.Len())
+	}
+	fun, results := SimpleSurvey()
+	tree.Survey([]*View{tree.View()}, fun)
+	if len(ps)-count != results.Len() {
+		t.Errorf("Circle delete %v, expecting %d remaining elements found %d", c, len(ps)-count, results.Len())
+	}
+}
+
+// Tests that surveying with a geo circle finds exactly the points within its great-circle radius,
+// including circles which cross the antimeridian or cover a pole
+func TestGeoCircleSurvey(t *testing.T) {
+	tree := NewQuadTree(-90, 90, -180, 180, treeLim)
+	ps := fillView(tree.View(), 10000)
+	for _, p := range ps {
+		tree.Insert(p.x, p.y, "test")
+	}
+	centres := []point{{0, 0}, {0, 179.9}, {0, -179.9}, {89.5, 0}, {-89.5, 90}, {51.5, -0.12}}
+	for _, centre := range centres {
+		for _, metres := range []float64{1000, 100000, 1000000, 5000000} {
+			c := NewGeoCircle(centre.x, centre.y, metres)
+			var count int
+			for _, p := range ps {
+				if haversine(centre.x, centre.y, p.x, p.y) <= metres {
+					count++
+				}
+			}
+			fun, results := SimpleSurvey()
+			tree.SurveyRegion(c, fun)
+			if count != results.Len() {
+				t.Errorf("Geo circle survey (%f,%f) %f metres, expecting %d elements found %d", centre.x, centre.y, metres, count, results.Len())
+			}
+		}
+	}
+}
+
+// Tests that difference and intersection contain exactly the points expected of their operands
+func TestRegionAlgebra(t *testing.T) {
+	v := OrigViewP(100, 100)
+	for i := 0; i < 100; i++ {
+		c1 := randomCircle(v)
+		c2 := randomCircle(v)
+		diff := Difference(c1, c2)
+		inter := Intersection(c1, c2)
+		for _, p := range fillView(v, 100) {
+			in1 := c1.contains(p.x, p.y)
+			in2 := c2.contains(p.x, p.y)
+			if diff.contains(p.x, p.y) != (in1 && !in2) {
+				t.Errorf("Difference of %v and %v incorrectly classifies (%f,%f)", c1, c2, p.x, p.y)
+			}
+			if inter.contains(p.x, p.y) != (in1 && in2) {
+				t.Errorf("Intersection of %v and %v incorrectly classifies (%f,%f)", c1, c2, p.x, p.y)
+			}
+		}
+	}
+}
+
+func randomCircle(v *View) *Circle {
+	x, y := randomPosition(v)
+	radius := testRand.Float64() * math.Max(v.width(), v.height()) / 2
+	return NewCircle(x, y, radius)
+}
+
+// Tests that a geo view contains exactly the points within its latitudes and, wrapping
+// around the globe, its longitudes
+func TestGeoView(t *testing.T) {
+	globe := NewViewP(-90, 90, -180, 180)
+	ps := fillView(globe, 10000)
+	bounds := [][4]float64{{-10, 10, -20, 20}, {-10, 10, 170, -170}, {-10, 10, 160, 200}, {30, 40, -200, -150}, {-90, 90, 0, 360}}
+	for _, b := range bounds {
+		gv := NewGeoView(b[0], b[1], b[2], b[3])
+		for _, p := range ps {
+			expected := p.x >= b[0] && p.x <= b[1] && lngBetween(p.y, b[2], b[3])
+			if gv.contains(p.x, p.y) != expected {
+				t.Errorf("Geo view %v incorrectly classifies (%f,%f)", b, p.x, p.y)
+			}
+		}
+	}
+}
+
+// Tests that a geo view passing over a pole continues down the far side of the globe
+func TestGeoViewPole(t *testing.T) {
+	north := NewGeoView(80, 95, -10, 10)
+	for _, p := range []point{{85, 0}, {89, 175}, {86, -171}} {
+		if !north.contains(p.x, p.y) {
+			t.Errorf("Geo view over the north pole %v, expecting to contain (%f,%f)", north, p.x, p.y)
+		}
+	}
+	for _, p := range []point{{84, 175}, {85, 90}, {79, 0}} {
+		if north.contains(p.x, p.y) {
+			t.Errorf("Geo view over the north pole %v, not expecting to contain (%f,%f)", north, p.x, p.y)
+		}
+	}
+	south := NewGeoView(-100, -85, 100, 120)
+	if !south.contains(-82, -70) || south.contains(-82, 110) {
+		t.Errorf("Geo view over the south pole %v, incorrectly classifies points beyond the pole", south)
+	}
+}
+
+// Tests that a geo view lying entirely beyond a pole only covers the far side of the globe
+func TestGeoViewBeyondPole(t *testing.T) {
+	north := NewGeoView(95, 100, 0, 10)
+	if !north.contains(82, -175) || north.contains(87, -175) || north.contains(82, 5) || north.contains(90, 5) {
+		t.Errorf("Geo view beyond the north pole %v, incorrectly classifies points", north)
+	}
+	south := NewGeoView(-100, -95, 0, 10)
+	if !south.contains(-82, -175) || south.contains(-87, -175) || south.contains(-82, 5) || south.contains(-90, 5) {
+		t.Errorf("Geo view beyond the south pole %v, incorrectly classifies points", south)
+	}
+}
+
+// Tests that surveying with a geo view near the antimeridian finds points on both sides of it
+func TestGeoViewSurvey(t *testing.T) {
+	tree := New[string](-90, 90, -180, 180, treeLim)
+	tree.Insert(0, 179.999, "east")
+	tree.Insert(0, -179.999, "west")
+	tree.Insert(0, 0, "meridian")
+	found := make(map[string]bool)
+	tree.SurveyRegion(NewGeoViewAround(0, 179.999, 1000), func(_, _ float64, e string) {
+		found[e] = true
+	})
+	if !found["east"] || !found["west"] || found["meridian"] {
+		t.Errorf("Geo view survey across the antimeridian, expecting east and west found %v", found)
+	}
+}
+
+// Indicates whether lng lies east of west and west of east, wrapping around the globe
+func lngBetween(lng, west, east float64) bool {
+	if east-west >= 360 {
+		return true
+	}
+	return math.Mod(lng-west+720, 360) <= math.Mod(east-west+720, 360)
+}
@@ -0,0 +1,121 @@
+package quadtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// Encodes strings as a uvarint length followed by the bytes of the string
+type stringCodec struct{}
+
+func (c stringCodec) Encode(w io.Writer, e interface{}) error {
+	s := e.(string)
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(len(s)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func (c stringCodec) Decode(r io.Reader) (interface{}, error) {
+	l, err := binary.ReadUvarint(r.(io.ByteReader))
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, l)
+	_, err = io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+// Tests that randomly filled trees are identical, element for element, after being written and read back
+func TestSerialiseRoundTrip(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		ps := fillView(tree.View(), 1000)
+		for i, p := range ps {
+			for d := 0; d < dups; d++ {
+				tree.Insert(p.x, p.y, strconv.Itoa(i)+"_"+strconv.Itoa(d))
+			}
+		}
+		var buf bytes.Buffer
+		if err := WriteTree[interface{}](&buf, tree, stringCodec{}); err != nil {
+			t.Errorf("Round trip, write failed %s", err.Error())
+			continue
+		}
+		read, err := ReadTree[interface{}](&buf, stringCodec{}, treeLim)
+		if err != nil {
+			t.Errorf("Round trip, read failed %s", err.Error())
+			continue
+		}
+		if !read.View().eq(tree.View()) {
+			t.Errorf("Round trip, expecting view %v found %v", tree.View(), read.View())
+		}
+		exp, found := surveyStrings(tree), surveyStrings(read)
+		if len(exp) != len(found) {
+			t.Errorf("Round trip, expecting %d elements found %d", len(exp), len(found))
+			continue
+		}
+		for i := range exp {
+			if exp[i] != found[i] {
+				t.Errorf("Round trip, expecting %s found %s", exp[i], found[i])
+				break
+			}
+		}
+	}
+}
+
+// Tests that an empty tree can be written and read back
+func TestSerialiseEmpty(t *testing.T) {
+	tree := NewQuadTree(-10, 10, -20, 20, treeLim)
+	var buf bytes.Buffer
+	if err := WriteTree[interface{}](&buf, tree, stringCodec{}); err != nil {
+		t.Fatalf("Empty round trip, write failed %s", err.Error())
+	}
+	read, err := ReadTree[interface{}](&buf, stringCodec{}, treeLim)
+	if err != nil {
+		t.Fatalf("Empty round trip, read failed %s", err.Error())
+	}
+	if len(surveyStrings(read)) != 0 {
+		t.Errorf("Empty round trip, found elements %v", surveyStrings(read))
+	}
+}
+
+// Tests that reading malformed or truncated input is reported as an error
+func TestSerialiseErrors(t *testing.T) {
+	tree := NewQuadTree(0, 10, 0, 10, treeLim)
+	tree.Insert(1, 1, "test")
+	var buf bytes.Buffer
+	WriteTree[interface{}](&buf, tree, stringCodec{})
+	valid := buf.Bytes()
+	badMagic := append([]byte("XXXX"), valid[4:]...)
+	if _, err := ReadTree[interface{}](bytes.NewReader(badMagic), stringCodec{}, treeLim); err != FormatErr {
+		t.Errorf("Bad magic, expecting %v found %v", FormatErr, err)
+	}
+	badVersion := append([]byte{}, valid...)
+	badVersion[len(serialMagic)] = serialVersion + 1
+	if _, err := ReadTree[interface{}](bytes.NewReader(badVersion), stringCodec{}, treeLim); err != FormatErr {
+		t.Errorf("Bad version, expecting %v found %v", FormatErr, err)
+	}
+	for l := 0; l < len(valid); l++ {
+		if _, err := ReadTree[interface{}](bytes.NewReader(valid[:l]), stringCodec{}, treeLim); err != io.ErrUnexpectedEOF {
+			t.Errorf("Truncated to %d bytes, expecting %v found %v", l, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+// Returns every element in tree, with its location, as a sorted slice of strings
+func surveyStrings(tree T) []string {
+	strs := make([]string, 0)
+	tree.Survey([]*View{tree.View()}, func(x, y float64, e interface{}) {
+		strs = append(strs, fmt.Sprintf("%v,%v,%v", x, y, e))
+	})
+	sort.Strings(strs)
+	return strs
+}
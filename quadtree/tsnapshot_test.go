@@ -0,0 +1,107 @@
+package quadtree
+
+import (
+	"sync"
+	"testing"
+)
+
+// Tests that a snapshot is unaffected by inserts, deletes and moves made to its tree after it was taken
+func TestSnapshotUnchanged(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		testSnapshotUnchanged(tree, t)
+	}
+	for _, tree := range makeConcurrentTrees() {
+		testSnapshotUnchanged(tree, t)
+	}
+}
+
+func testSnapshotUnchanged(tree T, t *testing.T) {
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	snap := tree.Snapshot()
+	x, y := randomPosition(tree.View())
+	nearest := tree.Nearest(x, y, 10, nil)
+	// Delete everything, point by point, then refill the tree so that any recycled nodes and leaves are reused
+	for _, p := range ps {
+		tree.Del(PointViewP(p.x, p.y), SimpleDelete())
+	}
+	nps := fillView(tree.View(), 1000)
+	for i, p := range nps {
+		tree.Insert(p.x, p.y, len(ps)+i)
+	}
+	for i := 0; i < len(nps); i += 2 {
+		nx, ny := randomPosition(tree.View())
+		moving := len(ps) + i
+		tree.Move(nps[i].x, nps[i].y, nx, ny, func(_, _ float64, e interface{}) bool {
+			return e == moving
+		})
+		nps[i] = point{nx, ny}
+	}
+	testSnapshotContents(snap, ps, t)
+	testSurveyScatter(tree, nps, 1, "Snapshot live tree", t)
+	snapNearest := snap.Nearest(x, y, 10, nil)
+	if len(snapNearest) != len(nearest) {
+		t.Errorf("Snapshot nearest, expecting %d elements found %d", len(nearest), len(snapNearest))
+		return
+	}
+	for i := range nearest {
+		if nearest[i] != snapNearest[i] {
+			t.Errorf("Snapshot nearest, expecting %v found %v", nearest, snapNearest)
+			return
+		}
+	}
+}
+
+// Tests that snapshots may be surveyed by other goroutines while their tree is modified
+func TestSnapshotConcurrent(t *testing.T) {
+	tree := New[int](0, 1000, 0, 1000, treeLim)
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		snap := tree.Snapshot()
+		expected := make([]point, len(ps))
+		copy(expected, ps)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				testSnapshotContents(snap, expected, t)
+			}
+		}()
+		for i := range ps {
+			x, y := randomPosition(tree.View())
+			moving := i
+			tree.Move(ps[i].x, ps[i].y, x, y, func(_, _ float64, e int) bool {
+				return e == moving
+			})
+			ps[i] = point{x, y}
+		}
+	}
+	wg.Wait()
+}
+
+// Tests that snap contains exactly one element, i, at ps[i] for each index of ps
+func testSnapshotContents[E comparable](snap *Snapshot[E], ps []point, t *testing.T) {
+	found := make(map[E]point)
+	snap.Survey([]*View{snap.View()}, func(x, y float64, e E) {
+		if _, ok := found[e]; ok {
+			t.Errorf("Snapshot, found element %v more than once", e)
+		}
+		found[e] = point{x, y}
+	})
+	if len(found) != len(ps) {
+		t.Errorf("Snapshot, expecting %d elements found %d", len(ps), len(found))
+	}
+	for e, p := range found {
+		i, ok := any(e).(int)
+		if !ok || i >= len(ps) || ps[i] != p {
+			t.Errorf("Snapshot, found unexpected element %v at (%f,%f)", e, p.x, p.y)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// Returns a tree dividing its leaves at the median over the view of each of testTrees
+func makeMedianTrees() []T {
+	trees := make([]T, len(testTrees))
+	for i, tree := range testTrees {
+		v := tree.View()
+		r := New[interface{}](v.lx, v.rx, v.ty, v.by, treeLim)
+		r.SetSplitStrategy(MedianSplit)
+		trees[i] = r
+	}
+	return trees
+}
+
+// Returns count points gathered into a few small cities across v
+func cities(v *View, count int) []point {
+	var ps []point
+	for c := 0; len(ps) < count; c++ {
+		x, y := randomPosition(v)
+		spread := v.width() / 1e4
+		x, y = clampTo(x, v.lx, v.rx-spread), clampTo(y, v.ty, v.by-spread)
+		ps = append(ps, crowd(x, y, spread, min(count/5+1, count-len(ps)))...)
+	}
+	return ps
+}
+
+// Tests that trees dividing their leaves at the median behave exactly like a plain quadtree
+func TestMedianSequential(t *testing.T) {
+	tests := []func(T, *testing.T){
+		testScatter,
+		testScatterDelete,
+		testGridAligned,
+		testSurveyUntil,
+		testNearest,
+		testCircleSurvey,
+		testMove,
+	}
+	for _, test := range tests {
+		for _, tree := range makeMedianTrees() {
+			test(tree, t)
+		}
+	}
+}
+
+// Tests that dividing at the median builds a shallower tree over clustered points
+// than quartering, leaving fewer leaves empty
+func TestMedianCities(t *testing.T) {
+	ps := cities(NewViewP(-90, 90, -180, 180), 5000)
+	depths := make(map[SplitStrategy]int)
+	empty := make(map[SplitStrategy]int)
+	for _, split := range []SplitStrategy{MidpointSplit, MedianSplit} {
+		tree := New[int](-90, 90, -180, 180, treeLim)
+		tree.SetSplitStrategy(split)
+		for i, p := range ps {
+			tree.Insert(p.x, p.y, i)
+		}
+		checkInvariants(tree, 0, t)
+		s := tree.Stats()
+		testStats(s, len(ps), len(ps), t)
+		depths[split] = s.MaxDepth()
+		empty[split] = s.Occupancy[0]
+	}
+	if depths[MedianSplit] >= depths[MidpointSplit] || empty[MedianSplit] >= empty[MidpointSplit] {
+		t.Errorf("Median cities, expecting a max depth less than %d and fewer than %d empty leaves, found %d and %d",
+			depths[MidpointSplit], empty[MidpointSplit], depths[MedianSplit], empty[MedianSplit])
+	}
+}
+
+// Tests that depth limits count divisions from the root after switching from median to
+// midpoint splits, so crowds overflow exactly at the limit
+func TestMedianThenMidpoint(t *testing.T) {
+	tree := New[int](0, modelSide, 0, modelSide, treeLim)
+	tree.SetSplitStrategy(MedianSplit)
+	ps := cities(tree.View(), 2000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	s := tree.Stats()
+	maxDepth := s.MaxDepth() + 4
+	tree.SetSplitStrategy(MidpointSplit)
+	tree.SetDepthLimits(maxDepth, 0)
+	for i := 0; i < len(ps); i += 100 {
+		for j, p := range crowd(ps[i].x, ps[i].y, 1e-9, 2*LEAF_SIZE) {
+			tree.Insert(p.x, p.y, len(ps)+i*LEAF_SIZE+j)
+		}
+	}
+	checkInvariants(tree, 0, t)
+	tree.Walk(func(tn *TreeNode[int]) bool {
+		if tn.Depth > maxDepth {
+			t.Errorf("Median then midpoint, %v lies at depth %d below the limit %d", &tn.View, tn.Depth, maxDepth)
+		}
+		locs := make(map[Point]bool)
+		for _, e := range tn.Entries {
+			locs[Point{e.X, e.Y}] = true
+		}
+		if len(locs) > LEAF_SIZE && tn.Depth != maxDepth {
+			t.Errorf("Median then midpoint, leaf %v overflows at depth %d, expecting depth %d", &tn.View, tn.Depth, maxDepth)
+		}
+		return true
+	})
+}
+
+// Tests that bulk loading a tree dividing at the median keeps every invariant
+func TestMedianBulk(t *testing.T) {
+	tree := New[int](0, modelSide, 0, modelSide, 10)
+	tree.SetSplitStrategy(MedianSplit)
+	tree.SetDepthLimits(8, 0)
+	ps := append(cities(tree.View(), 2000), crowd(100, 100, 1e-9, 100)...)
+	entries := make([]Entry[int], len(ps))
+	for i, p := range ps {
+		entries[i] = Entry[int]{X: p.x, Y: p.y, Elem: i}
+	}
+	tree.InsertMany(entries)
+	checkInvariants(tree, 0, t)
+	if s := tree.Stats(); s.Elems != len(ps) || s.MaxDepth() > 8 {
+		t.Errorf("Median bulk, expecting %d elements no deeper than 8 %v", len(ps), s.String())
+	}
+}
+
+// Tests the coordinate chosen to divide sets of points
+func TestMedianSplitAt(t *testing.T) {
+	for _, m := range []struct {
+		cs           []float64
+		lo, hi, cell float64
+		exp          float64
+	}{
+		{[]float64{1, 2, 3, 4}, 0, 10, 0, 2.5},
+		{[]float64{4, 3, 2, 1, 5}, 0, 10, 0, 2.5},
+		{[]float64{1, 1, 1, 9}, 0, 10, 0, 5},
+		{[]float64{1, 1, 1, 1}, 0, 10, 0, 5},
+		{[]float64{0, 0, 0, 10}, 0, 10, 0, 5},
+		{[]float64{0, 0, 10, 10}, 0, 10, 0, 5},
+		{[]float64{1, 2, 3, 4}, 0, 10, 3, 3},
+		{[]float64{7, 8, 9}, 0, 10, 2, 7.5},
+		{[]float64{7, 8, 9}, 0, 10, 5, 5},
+		{[]float64{0, 1}, 0, 10, 0, 0.5},
+		{[]float64{}, 0, 10, 0, 5},
+	} {
+		if c := medianSplit(append([]float64{}, m.cs...), m.lo, m.hi, m.cell); c != m.exp {
+			t.Errorf("Median split of %v in [%v,%v] with cells of %v, expecting %v found %v", m.cs, m.lo, m.hi, m.cell, m.exp, c)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// Tests the statistics of a tree which has never had an element inserted
+func TestEmptyStats(t *testing.T) {
+	tree := New[int](0, 100, 0, 100, treeLim)
+	s := tree.Stats()
+	if s.Nodes != 1 || s.Leaves != 4 {
+		t.Errorf("Empty stats, expecting 1 node and 4 leaves, found %d and %d", s.Nodes, s.Leaves)
+	}
+	if s.Points != 0 || s.Elems != 0 {
+		t.Errorf("Empty stats, expecting 0 points and 0 elems, found %d and %d", s.Points, s.Elems)
+	}
+	if s.MaxDepth() != 1 || s.LeafDepths[1] != 4 {
+		t.Errorf("Empty stats, expecting 4 leaves at depth 1, found leaf depths %v", s.LeafDepths)
+	}
+	if s.Occupancy[0] != 4 {
+		t.Errorf("Empty stats, expecting 4 empty leaves, found occupancy %v", s.Occupancy)
+	}
+	if s.PoolExhausted() {
+		t.Errorf("Empty stats, pool reported exhausted %v", s.String())
+	}
+}
+
+// Tests that the statistics of a filled tree agree with its contents and with each other
+func TestStats(t *testing.T) {
+	clearTrees()
+	for _, tree := range testTrees {
+		ps := fillView(tree.View(), 1000)
+		for i, p := range ps {
+			for d := 0; d < dups; d++ {
+				tree.Insert(p.x, p.y, i)
+			}
+		}
+		testStats(tree.(*QuadTree[interface{}]).Stats(), len(ps), len(ps)*dups, t)
+	}
+}
+
+// Tests that a tree with too small an allocation reports heap allocated nodes and leaves
+func TestExhaustedStats(t *testing.T) {
+	tree := New[int](0, 10, 0, 10, 10)
+	ps := fillView(tree.View(), 1000)
+	for i, p := range ps {
+		tree.Insert(p.x, p.y, i)
+	}
+	s := tree.Stats()
+	testStats(s, len(ps), len(ps), t)
+	if !s.PoolExhausted() {
+		t.Errorf("Exhausted stats, pool not reported exhausted %v", s.String())
+	}
+	if s.DisposableNodes == 0 || s.DisposableLeaves == 0 {
+		t.Errorf("Exhausted stats, expecting disposable nodes and leaves %v", s.String())
+	}
+}
+
+func testStats(s Stats, points, elems int, t *testing.T) {
+	if s.Points != points || s.Elems != elems {
+		t.Errorf("Stats, expecting %d points and %d elems found %d and %d", points, elems, s.Points, s.Elems)
+	}
+	if s.Leaves != 3*s.Nodes+1 {
+		t.Errorf("Stats, expecting %d leaves for %d nodes found %d", 3*s.Nodes+1, s.Nodes, s.Leaves)
+	}
+	leaves, occupied, depthLeaves := 0, 0, 0
+	for i, o := range s.Occupancy {
+		leaves += o
+		occupied += i * o
+	}
+	for _, d := range s.LeafDepths {
+		depthLeaves += d
+	}
+	leaves += s.OverflowLeaves
+	if leaves != s.Leaves || depthLeaves != s.Leaves {
+		t.Errorf("Stats, expecting %d leaves found %d by occupancy and %d by depth", s.Leaves, leaves, depthLeaves)
+	}
+	if s.OverflowLeaves == 0 && occupied != s.Points {
+		t.Errorf("Stats, expecting %d points found %d by occupancy", s.Points, occupied)
+	}
+	if s.OverflowLeaves > 0 && occupied+(LEAF_SIZE+1)*s.OverflowLeaves > s.Points {
+		t.Errorf("Stats, expecting at most %d points found %d by occupancy and %d overflowing leaves", s.Points, occupied, s.OverflowLeaves)
+	}
+}
@@ -0,0 +1,133 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+// Returns up to n random views, with whole numbered corners, lying within a 10 by 10 square
+// Some of the views have no width or height.
+func randomWholeViews(n int) []*View {
+	vs := make([]*View, 1+testRand.Intn(n))
+	for i := range vs {
+		lx, ty := float64(testRand.Intn(10)), float64(testRand.Intn(10))
+		rx, by := lx+float64(testRand.Intn(6)), ty+float64(testRand.Intn(6))
+		vs[i] = NewViewP(lx, rx, ty, by)
+	}
+	return vs
+}
+
+// Checks that no two views in s overlap, except along their borders, and that s is ordered
+func checkNormalised(s ViewSet, msg string, t *testing.T) {
+	for i, v := range s {
+		if i > 0 && (s[i-1].lx > v.lx || (s[i-1].lx == v.lx && s[i-1].ty > v.ty)) {
+			t.Errorf("%s, views %v and %v are out of order", msg, s[i-1], v)
+		}
+		for _, ov := range s[i+1:] {
+			if max(v.lx, ov.lx) < min(v.rx, ov.rx) && max(v.ty, ov.ty) < min(v.by, ov.by) {
+				t.Errorf("%s, views %v and %v overlap", msg, v, ov)
+			}
+			if v.covers(ov) || ov.covers(v) {
+				t.Errorf("%s, one of views %v and %v covers the other", msg, v, ov)
+			}
+		}
+	}
+}
+
+// Tests that the union, intersection and subtraction of random sets of views contain
+// exactly the points expected, and are normalised
+func TestViewSetAlgebra(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		a, b := randomWholeViews(6), randomWholeViews(6)
+		sa, sb := NewViewSet(a...), NewViewSet(b...)
+		union, inter, sub := sa.Union(sb), sa.Intersect(sb), sa.Subtract(sb)
+		for _, s := range []ViewSet{sa, sb, union, inter, sub} {
+			checkNormalised(s, "View set", t)
+		}
+		// Views contain their borders, so points on whole numbers are checked for
+		// union and intersection, and only points between whole numbers for subtraction
+		for x := -0.5; x <= 16; x += 0.5 {
+			for y := -0.5; y <= 16; y += 0.5 {
+				inA, inB := contains(a, x, y), contains(b, x, y)
+				if sa.contains(x, y) != inA {
+					t.Errorf("View set %v of %v, expecting contains (%f,%f) to be %v", sa, a, x, y, inA)
+				}
+				if union.contains(x, y) != (inA || inB) {
+					t.Errorf("Union %v of %v and %v, expecting contains (%f,%f) to be %v", union, a, b, x, y, inA || inB)
+				}
+				if inter.contains(x, y) != (inA && inB) {
+					t.Errorf("Intersection %v of %v and %v, expecting contains (%f,%f) to be %v", inter, a, b, x, y, inA && inB)
+				}
+				if x != float64(int(x)) && y != float64(int(y)) && sub.contains(x, y) != (inA && !inB) {
+					t.Errorf("Subtraction %v of %v from %v, expecting contains (%f,%f) to be %v", sub, b, a, x, y, inA && !inB)
+				}
+			}
+		}
+	}
+}
+
+// Tests that views with no area are kept, unless they lie within another view
+func TestViewSetPoints(t *testing.T) {
+	square := NewViewP(0, 10, 0, 10)
+	if s := NewViewSet(square, PointViewP(5, 5), PointViewP(10, 10), NewViewP(0, 10, 10, 10)); len(s) != 1 || !s[0].eq(square) {
+		t.Errorf("View set, expecting only %v, found %v", square, s)
+	}
+	s := NewViewSet(square, PointViewP(20, 5), NewViewP(10, 10, 5, 15))
+	exp := []*View{square, NewViewP(10, 10, 10, 15), PointViewP(20, 5)}
+	if len(s) != len(exp) {
+		t.Fatalf("View set, expecting %v, found %v", exp, s)
+	}
+	for i := range exp {
+		if !s[i].eq(exp[i]) {
+			t.Errorf("View set, expecting %v, found %v", exp, s)
+		}
+	}
+	if s := NewViewSet(invalidView, nil); len(s) != 0 {
+		t.Errorf("View set, expecting invalid views to be ignored, found %v", s)
+	}
+}
+
+// Tests that neighbouring views covering the same rows are joined
+func TestViewSetJoin(t *testing.T) {
+	s := NewViewSet(NewViewP(0, 1, 0, 2), NewViewP(1, 3, 0, 2), NewViewP(2, 5, 0, 2))
+	if len(s) != 1 || !s[0].eq(NewViewP(0, 5, 0, 2)) {
+		t.Errorf("View set, expecting a single view [0 5 0 2], found %v", s)
+	}
+	s = NewViewSet(NewViewP(0, 4, 0, 4)).Subtract(NewViewSet(NewViewP(1, 2, 1, 2)))
+	if len(s) != 4 {
+		t.Errorf("View set, expecting 4 views around a hole, found %v", s)
+	}
+}
+
+// Tests that a ViewSet can be surveyed, counted and deleted from in a quadtree
+func TestViewSetSurvey(t *testing.T) {
+	for _, tree := range []T{NewQuadTree(0, 100, 0, 100, treeLim), NewHashGrid[interface{}](0, 100, 0, 100, 7, 11), NewZOrderIndex[interface{}](0, 100, 0, 100)} {
+		ps := fillView(tree.View(), 1000)
+		for i, p := range ps {
+			tree.Insert(p.x, p.y, i)
+		}
+		s := NewViewSet(NewViewP(10, 60, 10, 60), NewViewP(40, 90, 40, 90)).Subtract(NewViewSet(NewViewP(20, 30, 0, 100)))
+		exp := 0
+		for _, p := range ps {
+			if s.contains(p.x, p.y) {
+				exp++
+			}
+		}
+		found := make(map[interface{}]bool)
+		tree.Survey(s, func(x, y float64, e interface{}) {
+			if found[e] {
+				t.Errorf("View set survey of %v, found %v more than once", s, e)
+			}
+			found[e] = true
+		})
+		if len(found) != exp {
+			t.Errorf("View set survey of %v, expecting %d elements, found %d", s, exp, len(found))
+		}
+		if c := tree.CountRegion(s); c != exp {
+			t.Errorf("View set count of %v, expecting %d elements, counted %d", s, exp, c)
+		}
+		tree.Del(s, func(_, _ float64, _ interface{}) bool { return true })
+		if c := tree.Count([]*View{tree.View()}); c != len(ps)-exp {
+			t.Errorf("View set delete of %v, expecting %d elements to remain, counted %d", s, len(ps)-exp, c)
+		}
+	}
+}
@@ -50,6 +50,18 @@ func contains(vs []*View, x, y float64) bool {
 	return false
 }
 
+//
+//	Determines if a view is covered by at least one of a slice of *View
+//
+func covers(vs []*View, oV *View) bool {
+	for _, v := range vs {
+		if v.covers(oV) {
+			return true
+		}
+	}
+	return false
+}
+
 //
 //	Determines if a view overlaps at least one of a slice of *View
 //
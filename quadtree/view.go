@@ -81,6 +81,14 @@ func (v *View) contains(x, y float64) bool {
 	return x >= v.lx && x <= v.rx && y <= v.by && y >= v.ty
 }
 
+// Returns the squared distance from the point (x,y) to the nearest point in v
+// A point lying inside v has a distance of 0
+func (v *View) distSq(x, y float64) float64 {
+	dx := math.Max(0, math.Max(v.lx-x, x-v.rx))
+	dy := math.Max(0, math.Max(v.ty-y, y-v.by))
+	return dx*dx + dy*dy
+}
+
 // Indicates whether any of the four edges
 // of ov pass through v
 func (v *View) xBy(ov *View) bool {
@@ -131,6 +139,38 @@ func (v *View) xH(y, lx, rx float64) bool {
 	return true
 }
 
+// Indicates whether any part of the line segment from (x1,y1) to (x2,y2) lies within v
+// This is the Liang-Barsky clipping test, the segment is clipped against each edge of v
+// in turn and if nothing remains the segment lies entirely outside v.
+func (v *View) crossedBy(x1, y1, x2, y2 float64) bool {
+	dx := x2 - x1
+	dy := y2 - y1
+	p := [4]float64{-dx, dx, -dy, dy}
+	q := [4]float64{x1 - v.lx, v.rx - x1, y1 - v.ty, v.by - y1}
+	t0, t1 := 0.0, 1.0
+	for i := range p {
+		if p[i] == 0 {
+			if q[i] < 0 {
+				return false
+			}
+			continue
+		}
+		t := q[i] / p[i]
+		if p[i] < 0 {
+			if t > t1 {
+				return false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return false
+			}
+			t1 = math.Min(t1, t)
+		}
+	}
+	return true
+}
+
 // One View overlaps with another if the two Views intersect at
 // their borders or if either is contained entirely within the other.
 // Reflexive, symmetric, and *not* transitive
@@ -144,6 +184,11 @@ func (v *View) overlaps(ov *View) bool {
 	return false
 }
 
+// Indicates whether ov lies entirely within v, including along v's borders
+func (v *View) covers(ov *View) bool {
+	return ov.lx >= v.lx && ov.rx <= v.rx && ov.ty >= v.ty && ov.by <= v.by
+}
+
 // Returns the width of the View
 func (v *View) width() float64 {
 	if v == nil {
@@ -164,16 +209,18 @@ func (v *View) height() float64 {
 // These four quarters completely cover v
 // TODO This function should return a slice of views created by dividing v an arbitrary number of times
 func (v *View) quarters() (v1, v2, v3, v4 *View) {
-	lx := v.lx
-	rx := v.rx
-	ty := v.ty
-	by := v.by
-	midx := lx + (rx-lx)/2
-	midy := ty + (by-ty)/2
-	v1 = NewViewP(lx, midx, ty, midy)
-	v2 = NewViewP(midx, rx, ty, midy)
-	v3 = NewViewP(lx, midx, midy, by)
-	v4 = NewViewP(midx, rx, midy, by)
+	midx := v.lx + (v.rx-v.lx)/2
+	midy := v.ty + (v.by-v.ty)/2
+	return v.divide(midx, midy)
+}
+
+// Returns four views representing v divided into four non-overlapping sections at (x,y)
+// (x,y) must lie within v. The sections completely cover v, in the same order as quarters
+func (v *View) divide(x, y float64) (v1, v2, v3, v4 *View) {
+	v1 = NewViewP(v.lx, x, v.ty, y)
+	v2 = NewViewP(x, v.rx, v.ty, y)
+	v3 = NewViewP(v.lx, x, y, v.by)
+	v4 = NewViewP(x, v.rx, y, v.by)
 	return
 }
 
@@ -184,8 +231,9 @@ func (v *View) eq(ov *View) bool {
 }
 
 // Returns a slice of views which satisfy:
-// 1: None are overlapping with themselves or with ov
+// 1: None are overlapping with ov, except along its borders
 // 2: When combined with ov they completely cover v
+// The views returned may overlap each other, see ViewSet.Subtract for non-overlapping views
 // Intuitively imagine v is make up every view in []*View returned plus ov
 // To subtract ov you just need to take it away and return the []*View
 func (v *View) Subtract(ov *View) []*View {
@@ -0,0 +1,211 @@
+package quadtree
+
+import (
+	"sort"
+)
+
+// A ViewSet is a set of Views used as a single Region, covering the union of its views.
+// The ViewSets returned by NewViewSet, Union, Intersect and Subtract are normalised.
+// Their views overlap each other only along their borders, neighbouring views are
+// merged wherever they form a single rectangle, and the views are ordered left to
+// right and then top to bottom.
+// Views contain their borders, so subtracting one set from another leaves within
+// the result any border the result shares with the set subtracted.
+// Because a ViewSet is a []*View it may be passed to Survey and Count, as well
+// as to SurveyRegion, CountRegion and Del.
+type ViewSet []*View
+
+// Returns a normalised ViewSet covering the union of vs
+func NewViewSet(vs ...*View) ViewSet {
+	return normalise(vs)
+}
+
+// Returns a normalised ViewSet covering every point in either s or os
+func (s ViewSet) Union(os ViewSet) ViewSet {
+	vs := make([]*View, 0, len(s)+len(os))
+	return normalise(append(append(vs, s...), os...))
+}
+
+// Returns a normalised ViewSet covering every point in both s and os
+func (s ViewSet) Intersect(os ViewSet) ViewSet {
+	var vs []*View
+	for _, v := range s {
+		for _, ov := range os {
+			lx, rx := max(v.lx, ov.lx), min(v.rx, ov.rx)
+			ty, by := max(v.ty, ov.ty), min(v.by, ov.by)
+			if lx <= rx && ty <= by {
+				vs = append(vs, NewViewP(lx, rx, ty, by))
+			}
+		}
+	}
+	return normalise(vs)
+}
+
+// Returns a normalised ViewSet covering every point in s which is not in os,
+// along with the borders the two sets share
+func (s ViewSet) Subtract(os ViewSet) ViewSet {
+	var vs []*View
+	for _, v := range s {
+		pieces := []*View{v}
+		for _, ov := range os {
+			var next []*View
+			for _, p := range pieces {
+				next = append(next, p.Subtract(ov)...)
+			}
+			pieces = next
+		}
+		vs = append(vs, pieces...)
+	}
+	return normalise(vs)
+}
+
+func (s ViewSet) contains(x, y float64) bool {
+	return contains(s, x, y)
+}
+
+func (s ViewSet) overlaps(v *View) bool {
+	return overlaps(s, v)
+}
+
+// Only a View covered by a single one of the set's views is reported as covered
+func (s ViewSet) covers(v *View) bool {
+	return covers(s, v)
+}
+
+// A span is the closed interval from lo to hi along the y axis
+type span struct {
+	lo, hi float64
+}
+
+// Returns the union of ss as the fewest spans, ordered from top to bottom
+// Spans which touch are joined.
+func mergeSpans(ss []span) []span {
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].lo < ss[j].lo
+	})
+	merged := make([]span, 0, len(ss))
+	for _, s := range ss {
+		if last := len(merged) - 1; last >= 0 && s.lo <= merged[last].hi {
+			merged[last].hi = max(merged[last].hi, s.hi)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// Returns the closure of each part of s lying outside of cut, which must be merged
+func subtractSpans(s span, cut []span) []span {
+	var parts []span
+	if s.lo == s.hi {
+		for _, c := range cut {
+			if c.lo <= s.lo && s.lo <= c.hi {
+				return nil
+			}
+		}
+		return append(parts, s)
+	}
+	from := s.lo
+	for _, c := range cut {
+		if c.hi < from {
+			continue
+		}
+		if c.lo >= s.hi {
+			break
+		}
+		if c.lo > from {
+			parts = append(parts, span{from, c.lo})
+		}
+		from = c.hi
+	}
+	if from < s.hi {
+		parts = append(parts, span{from, s.hi})
+	}
+	return parts
+}
+
+// Indicates whether ss and os hold exactly the same spans
+func sameSpans(ss, os []span) bool {
+	if len(ss) != len(os) {
+		return false
+	}
+	for i := range ss {
+		if ss[i] != os[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the merged spans of each view in vs crossing every x from lx to rx
+func spansAcross(vs []*View, lx, rx float64) []span {
+	var ss []span
+	for _, v := range vs {
+		if v.lx <= lx && v.rx >= rx {
+			ss = append(ss, span{v.ty, v.by})
+		}
+	}
+	return mergeSpans(ss)
+}
+
+// Returns a normalised ViewSet covering the union of vs
+// Invalid views, like those returned by View.Intersect, are ignored.
+// The plane is cut into slabs at the left and right edges of every view. The spans
+// covered within each slab are merged, and runs of neighbouring slabs covering the
+// same spans are joined into single views. A view with no width lies on the edge
+// between two slabs, only the parts of it lying outside both slabs are kept.
+func normalise(vs []*View) ViewSet {
+	live := make([]*View, 0, len(vs))
+	var xs []float64
+	for _, v := range vs {
+		if v != nil && v.valid {
+			live = append(live, v)
+			xs = append(xs, v.lx, v.rx)
+		}
+	}
+	sort.Float64s(xs)
+	unique := xs[:0]
+	for i, x := range xs {
+		if i == 0 || x != xs[i-1] {
+			unique = append(unique, x)
+		}
+	}
+	xs = unique
+	slabs := make([][]span, max(len(xs)-1, 0))
+	for i := range slabs {
+		slabs[i] = spansAcross(live, xs[i], xs[i+1])
+	}
+	set := ViewSet{}
+	for i := 0; i < len(slabs); {
+		j := i + 1
+		for j < len(slabs) && sameSpans(slabs[i], slabs[j]) {
+			j++
+		}
+		for _, s := range slabs[i] {
+			set = append(set, NewViewP(xs[i], xs[j], s.lo, s.hi))
+		}
+		i = j
+	}
+	for i, x := range xs {
+		var cut []span
+		if i > 0 {
+			cut = append(cut, slabs[i-1]...)
+		}
+		if i < len(slabs) {
+			cut = append(cut, slabs[i]...)
+		}
+		cut = mergeSpans(cut)
+		for _, s := range spansAcross(live, x, x) {
+			for _, part := range subtractSpans(s, cut) {
+				set = append(set, NewViewP(x, x, part.lo, part.hi))
+			}
+		}
+	}
+	sort.Slice(set, func(i, j int) bool {
+		if set[i].lx != set[j].lx {
+			return set[i].lx < set[j].lx
+		}
+		return set[i].ty < set[j].ty
+	})
+	return set
+}
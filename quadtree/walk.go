@@ -0,0 +1,61 @@
+package quadtree
+
+// A TreeNode describes a single node or leaf of a quadtree, as visited by Walk
+type TreeNode[E any] struct {
+	// The area covered by this node or leaf
+	View View
+	// The root node lies at depth 0
+	Depth int
+	// Only leaves hold elements, every node has four children
+	Leaf bool
+	// Each element held by a leaf, with its location
+	// Elements sharing a location are adjacent to each other.
+	Entries []Entry[E]
+}
+
+// A Walker is a quadtree, or a snapshot of one, whose structure can be walked
+// *QuadTree and *Snapshot both implement Walker.
+type Walker[E any] interface {
+	View() *View
+	Walk(visit func(tn *TreeNode[E]) bool)
+}
+
+// Calls visit on every node and leaf of this tree, each node before its children
+// If visit returns false for a node its children are skipped.
+// The TreeNode passed to visit must not be kept after visit returns.
+func (r *QuadTree[E]) Walk(visit func(tn *TreeNode[E]) bool) {
+	walk(r.rootNode, 0, visit)
+}
+
+// Calls visit on every node and leaf of this snapshot, each node before its children
+// If visit returns false for a node its children are skipped.
+// The TreeNode passed to visit must not be kept after visit returns.
+func (s *Snapshot[E]) Walk(visit func(tn *TreeNode[E]) bool) {
+	for _, root := range s.roots {
+		walk(root, 0, visit)
+	}
+}
+
+// Calls visit on st, which lies at depth, and then on each of its descendants
+func walk[E any](st subtree[E], depth int, visit func(tn *TreeNode[E]) bool) {
+	switch st.(type) {
+	case *leaf[E]:
+		l := st.(*leaf[E])
+		tn := &TreeNode[E]{View: l.view, Depth: depth, Leaf: true}
+		for i := range l.ps {
+			p := &l.ps[i]
+			for _, e := range p.elems {
+				tn.Entries = append(tn.Entries, Entry[E]{X: p.x, Y: p.y, Elem: e})
+			}
+		}
+		visit(tn)
+	case *node[E]:
+		n := st.(*node[E])
+		if !visit(&TreeNode[E]{View: n.view, Depth: depth}) {
+			return
+		}
+		for i := range n.children {
+			walk(n.children[i], depth+1, visit)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package quadtree
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"sort"
+)
+
+// The number of bits each coordinate is quantised to in a Z-order key
+const zBits = 32
+
+// The number of quanta along each axis of a ZOrderIndex
+const zQuanta = uint64(1) << zBits
+
+// A zentry is a single element stored in a ZOrderIndex, with its location and Z-order key
+// A deleted entry is marked dead and left in place until its run is compacted.
+type zentry[E any] struct {
+	key  uint64
+	x, y float64
+	elem E
+	dead bool
+}
+
+// A zrun is a slice of entries sorted by key, dead counts the entries marked dead
+type zrun[E any] struct {
+	entries []zentry[E]
+	dead    int
+}
+
+// Returns the number of entries in this run which haven't been deleted
+func (run *zrun[E]) live() int {
+	return len(run.entries) - run.dead
+}
+
+// Removes the dead entries from this run
+func (run *zrun[E]) compact() {
+	kept := 0
+	for i := range run.entries {
+		if !run.entries[i].dead {
+			run.entries[kept] = run.entries[i]
+			kept++
+		}
+	}
+	clear(run.entries[kept:])
+	run.entries = run.entries[:kept]
+	run.dead = 0
+}
+
+// Returns the index of the first entry, among entries lo up to hi, whose key is at least key
+func (run *zrun[E]) search(lo, hi int, key uint64) int {
+	return lo + sort.Search(hi-lo, func(i int) bool {
+		return run.entries[lo+i].key >= key
+	})
+}
+
+// Returns a new run holding the live entries of runs a and b, merged in key order
+// Entries from a come before entries from b with the same key.
+func mergeRuns[E any](a, b *zrun[E]) zrun[E] {
+	merged := make([]zentry[E], 0, a.live()+b.live())
+	i, j := 0, 0
+	for i < len(a.entries) || j < len(b.entries) {
+		var ze *zentry[E]
+		if j == len(b.entries) || (i < len(a.entries) && a.entries[i].key <= b.entries[j].key) {
+			ze = &a.entries[i]
+			i++
+		} else {
+			ze = &b.entries[j]
+			j++
+		}
+		if !ze.dead {
+			merged = append(merged, *ze)
+		}
+	}
+	return zrun[E]{entries: merged}
+}
+
+// A ZOrderIndex is a spatial index holding its elements sorted by their Z-order, or
+// Morton, key. The key interleaves the bits of an element's x and y coordinates, each
+// quantised to zBits bits, so elements close together on the plane tend to lie close
+// together in key order, as with a geohash.
+// Every square box of quanta whose side and corner are aligned to a power of two holds
+// a contiguous run of keys. Surveys divide the view into such boxes, like the nodes of
+// a quadtree, finding the entries in each box by binary search and pruning boxes which
+// don't overlap the region surveyed.
+// Rather than one sorted slice, which every insert would have to shift, the elements
+// are kept in a few sorted runs. Each insert adds a run of one element, and whenever a
+// run holds no more elements than the run after it the two are merged, like carrying
+// in a binary counter. So there are about log2(n) runs, and each element is merged
+// about log2(n) times. Deleted elements are marked dead, and a run is compacted once
+// half of its entries are dead.
+// When E is interface{} a ZOrderIndex implements the public interface T.
+// A ZOrderIndex is always Bounded.
+type ZOrderIndex[E any] struct {
+	view View
+	// From the largest, and oldest, run to the smallest and newest
+	runs []zrun[E]
+	size int
+}
+
+// Returns a new empty ZOrderIndex, storing elements of type E, whose View extends from
+// leftX to rightX across the x axis and
+// topY down to bottomY along the y axis
+// leftX < rightX
+// topY < bottomY
+func NewZOrderIndex[E any](leftX, rightX, topY, bottomY float64) *ZOrderIndex[E] {
+	return &ZOrderIndex[E]{view: NewView(leftX, rightX, topY, bottomY)}
+}
+
+// Returns the coordinate of the lower edge of quantum q along an axis from lo to hi
+// q == zQuanta gives hi.
+func zEdge(lo, hi float64, q uint64) float64 {
+	if q >= zQuanta {
+		return hi
+	}
+	return lo + float64(q)*((hi-lo)/float64(zQuanta))
+}
+
+// Returns the quantum containing c along an axis from lo to hi, c must lie within lo and hi
+// The quantum is checked against the same edges used to build box views, so the view of
+// a box always contains every element in it.
+func zQuantum(lo, hi, c float64) uint64 {
+	if !(hi > lo) {
+		return 0
+	}
+	q := uint64(min(math.Max((c-lo)/(hi-lo)*float64(zQuanta), 0), float64(zQuanta-1)))
+	for q > 0 && c < zEdge(lo, hi, q) {
+		q--
+	}
+	for q < zQuanta-1 && c >= zEdge(lo, hi, q+1) {
+		q++
+	}
+	return q
+}
+
+// Spreads the low 32 bits of v out to the even bits of the result
+func zSpread(v uint64) uint64 {
+	v &= 0xFFFFFFFF
+	v = (v | v<<16) & 0x0000FFFF0000FFFF
+	v = (v | v<<8) & 0x00FF00FF00FF00FF
+	v = (v | v<<4) & 0x0F0F0F0F0F0F0F0F
+	v = (v | v<<2) & 0x3333333333333333
+	v = (v | v<<1) & 0x5555555555555555
+	return v
+}
+
+// Returns the Z-order key of the quanta qx and qy, x takes the even bits and y the odd
+func zKey(qx, qy uint64) uint64 {
+	return zSpread(qx) | zSpread(qy)<<1
+}
+
+// Returns the Z-order key of (x,y), which must lie within this index's view
+func (z *ZOrderIndex[E]) key(x, y float64) uint64 {
+	return zKey(zQuantum(z.view.lx, z.view.rx, x), zQuantum(z.view.ty, z.view.by, y))
+}
+
+// Returns the view of the box of quanta side wide whose top left quantum is (qx,qy)
+func (z *ZOrderIndex[E]) boxView(qx, qy, side uint64) *View {
+	return NewViewP(zEdge(z.view.lx, z.view.rx, qx), zEdge(z.view.lx, z.view.rx, qx+side), zEdge(z.view.ty, z.view.by, qy), zEdge(z.view.ty, z.view.by, qy+side))
+}
+
+// Calls fun on each stretch of run's entries, lo up to hi, held by a box overlapping reg.
+// The box of quanta side wide, with top left quantum (qx,qy), holds entries lo up to hi.
+// A box covered by reg is passed to fun whole, with covered set. A box holding no more than
+// LEAF_SIZE entries, or only a single quantum, is passed to fun to be scanned. Otherwise
+// the box is divided into its four quarters, in key order, and each is visited in turn.
+// fun must skip dead entries.
+// Stops as soon as fun returns false.
+// Returns false if fun returned false, true otherwise.
+func (z *ZOrderIndex[E]) visit(run *zrun[E], reg Region, qx, qy, side uint64, lo, hi int, fun func(lo, hi int, covered bool) bool) bool {
+	if lo == hi {
+		return true
+	}
+	v := z.boxView(qx, qy, side)
+	if !reg.overlaps(v) {
+		return true
+	}
+	if reg.covers(v) {
+		return fun(lo, hi, true)
+	}
+	if hi-lo <= LEAF_SIZE || side == 1 {
+		return fun(lo, hi, false)
+	}
+	half := side / 2
+	span := half * half
+	base := zKey(qx, qy)
+	for c := uint64(0); c < 4; c++ {
+		end := hi
+		if c < 3 {
+			end = run.search(lo, hi, base+(c+1)*span)
+		}
+		if !z.visit(run, reg, qx+(c&1)*half, qy+(c>>1)*half, half, lo, end, fun) {
+			return false
+		}
+		lo = end
+	}
+	return true
+}
+
+// Calls fun on each stretch of entries, in each run, held by a box overlapping reg, see visit
+// fun is given the run holding the entries.
+func (z *ZOrderIndex[E]) visitAll(reg Region, fun func(run *zrun[E], lo, hi int, covered bool) bool) bool {
+	for i := range z.runs {
+		run := &z.runs[i]
+		more := z.visit(run, reg, 0, 0, zQuanta, 0, len(run.entries), func(lo, hi int, covered bool) bool {
+			return fun(run, lo, hi, covered)
+		})
+		if !more {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the View for this index
+func (z *ZOrderIndex[E]) View() *View {
+	return &z.view
+}
+
+// Inserts e into this index at (x,y)
+// Returns an error if (x,y) lies outside this index
+func (z *ZOrderIndex[E]) Insert(x, y float64, e E) error {
+	if !z.view.contains(x, y) {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, x, y, &z.view)
+	}
+	z.insert(x, y, e)
+	return nil
+}
+
+// Adds e at (x,y), which must lie within this index's view, as a new run
+// The newest runs are then merged while each is no larger than the run after it.
+func (z *ZOrderIndex[E]) insert(x, y float64, e E) {
+	z.runs = append(z.runs, zrun[E]{entries: []zentry[E]{{key: z.key(x, y), x: x, y: y, elem: e}}})
+	z.size++
+	for n := len(z.runs); n > 1 && z.runs[n-2].live() <= z.runs[n-1].live(); n-- {
+		z.runs[n-2] = mergeRuns(&z.runs[n-2], &z.runs[n-1])
+		z.runs[n-1] = zrun[E]{}
+		z.runs = z.runs[:n-1]
+	}
+}
+
+// Applies fun to every element occurring within any view in vs in this index
+func (z *ZOrderIndex[E]) Survey(vs []*View, fun func(x, y float64, e E)) {
+	z.SurveyRegion(views(vs), fun)
+}
+
+// Applies fun to every element occurring within reg in this index
+func (z *ZOrderIndex[E]) SurveyRegion(reg Region, fun func(x, y float64, e E)) {
+	z.SurveyUntil(reg, func(x, y float64, e E) bool {
+		fun(x, y, e)
+		return true
+	})
+}
+
+// Applies fun to every element occurring within reg in this index, stopping
+// as soon as fun returns false
+func (z *ZOrderIndex[E]) SurveyUntil(reg Region, fun func(x, y float64, e E) bool) {
+	z.visitAll(reg, func(run *zrun[E], lo, hi int, covered bool) bool {
+		for i := lo; i < hi; i++ {
+			ze := &run.entries[i]
+			if !ze.dead && (covered || reg.contains(ze.x, ze.y)) && !fun(ze.x, ze.y, ze.elem) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Returns an iterator over the location of, and each element occurring within, reg in this index
+// The index must not be modified while iterating.
+func (z *ZOrderIndex[E]) Within(reg Region) iter.Seq2[Point, E] {
+	return func(yield func(Point, E) bool) {
+		z.SurveyUntil(reg, func(x, y float64, e E) bool {
+			return yield(Point{x, y}, e)
+		})
+	}
+}
+
+// Returns the number of elements occurring within any view in vs in this index
+func (z *ZOrderIndex[E]) Count(vs []*View) int {
+	return z.CountRegion(views(vs))
+}
+
+// Returns the number of elements occurring within reg in this index
+// Boxes lying inside reg are counted without visiting their elements, unless their
+// run holds dead entries.
+func (z *ZOrderIndex[E]) CountRegion(reg Region) int {
+	count := 0
+	z.visitAll(reg, func(run *zrun[E], lo, hi int, covered bool) bool {
+		if covered && run.dead == 0 {
+			count += hi - lo
+			return true
+		}
+		for i := lo; i < hi; i++ {
+			ze := &run.entries[i]
+			if !ze.dead && (covered || reg.contains(ze.x, ze.y)) {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// Returns the number of elements lying within each cell of a grid of xCells by yCells laid over v
+// Providing fewer than one cell along either axis will cause a panic
+func (z *ZOrderIndex[E]) Heatmap(v *View, xCells, yCells int) [][]int {
+	h := newHeatmap(v, xCells, yCells)
+	z.SurveyRegion(v, func(x, y float64, _ E) {
+		h.add(x, y, 1)
+	})
+	return h.counts
+}
+
+// Returns clusters of the elements lying within v, each covering an area no wider or taller than resolution
+// Each cluster holds its centroid, the number of elements in it and up to reps of those elements.
+// Clusters are the cells of a grid, resolution wide and high, laid over v.
+// Providing a resolution which isn't positive, or a negative reps, will cause a panic
+func (z *ZOrderIndex[E]) Clusters(v *View, resolution float64, reps int) []Cluster[E] {
+	c := newClusterer[E](v, resolution, reps)
+	c.addSurvey(z.SurveyRegion)
+	return c.clusters
+}
+
+// Dels each element, e, in this index which lies within reg and for which pred(e) returns true
+// Deleted entries are marked dead. Any run left at least half dead is compacted, and
+// any run left empty is removed.
+func (z *ZOrderIndex[E]) Del(reg Region, pred func(x, y float64, e E) bool) {
+	z.visitAll(reg, func(run *zrun[E], lo, hi int, covered bool) bool {
+		for i := lo; i < hi; i++ {
+			ze := &run.entries[i]
+			if !ze.dead && (covered || reg.contains(ze.x, ze.y)) && pred(ze.x, ze.y, ze.elem) {
+				ze.dead = true
+				run.dead++
+				z.size--
+			}
+		}
+		return true
+	})
+	kept := 0
+	for i := range z.runs {
+		run := &z.runs[i]
+		if run.dead*2 >= len(run.entries) {
+			run.compact()
+		}
+		if len(run.entries) > 0 {
+			z.runs[kept] = *run
+			kept++
+		}
+	}
+	clear(z.runs[kept:])
+	z.runs = z.runs[:kept]
+}
+
+// Moves each element, e, lying at (oldX,oldY) for which pred(e) returns true to (newX,newY)
+// If (newX,newY) lies outside this index nothing is moved and an error is returned.
+func (z *ZOrderIndex[E]) Move(oldX, oldY, newX, newY float64, pred func(x, y float64, e E) bool) error {
+	if !z.view.contains(newX, newY) {
+		return fmt.Errorf("%w: (%.3f,%.3f) outside %v", OutOfBoundsErr, newX, newY, &z.view)
+	}
+	moved := make([]E, 0, 1)
+	z.Del(PointViewP(oldX, oldY), func(x, y float64, e E) bool {
+		if pred(x, y, e) {
+			moved = append(moved, e)
+			return true
+		}
+		return false
+	})
+	for _, e := range moved {
+		z.insert(newX, newY, e)
+	}
+	return nil
+}
+
+// Returns up to k elements ordered by their distance from (x,y), nearest first
+// Only elements for which filter returns true are returned, filter may be nil
+// Squares around (x,y), growing ever larger, are searched until the k nearest
+// elements are known, see nearestBySquares. The first square is sized to hold
+// about k elements, were the elements spread evenly.
+func (z *ZOrderIndex[E]) Nearest(x, y float64, k int, filter func(x, y float64, e E) bool) []E {
+	side := math.Max(z.view.width(), z.view.height())
+	if z.size > 0 {
+		side *= math.Sqrt(float64(k) / float64(z.size))
+	}
+	return nearestBySquares(&z.view, x, y, side, k, filter, z.SurveyRegion)
+}
+
+// Returns a snapshot of this index as it is now
+// An index can't share its entries with a snapshot, so every element is copied.
+func (z *ZOrderIndex[E]) Snapshot() *Snapshot[E] {
+	return snapshotOf(&z.view, z.size, z.SurveyRegion)
+}
+
+// Returns a human friendly string representation of the live entries of each run of this index, in key order
+func (z *ZOrderIndex[E]) String() string {
+	str := z.view.String()
+	for i := range z.runs {
+		str += "\n"
+		for j := range z.runs[i].entries {
+			ze := &z.runs[i].entries[j]
+			if !ze.dead {
+				str += fmt.Sprintf("(%016x %v,%.3f,%.3f)", ze.key, ze.elem, ze.x, ze.y)
+			}
+		}
+	}
+	return str
+}